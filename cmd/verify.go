@@ -17,10 +17,15 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/redhat-certification/chart-verifier/pkg/chartverifier/checks"
 	"github.com/redhat-certification/chart-verifier/pkg/chartverifier/profiles"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
 
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/cli/values"
@@ -56,6 +61,14 @@ var (
 	openshiftVersionFlag string
 	// output logs flag
 	outputLogs bool
+	// parallelismFlag is the maximum number of checks running concurrently.
+	parallelismFlag int
+	// sortByNameFlag sorts the checks of the report by name rather than in the order of the profile.
+	sortByNameFlag bool
+	// failFastFlag stops the verification at the first check returning an error, rather than reporting it.
+	failFastFlag bool
+	// kubeOpenShiftVersionsFlag is the file replacing the bundled Kubernetes-OpenShift versions.
+	kubeOpenShiftVersionsFlag string
 )
 
 func filterChecks(set profiles.FilteredRegistry, subset []string, setEnabled bool, subsetEnabled bool) (chartverifier.FilteredRegistry, error) {
@@ -136,13 +149,21 @@ func NewVerifyCmd(config *viper.Viper) *cobra.Command {
 				SetChecks(checks).
 				SetToolVersion(Version).
 				SetOpenShiftVersion(openshiftVersionFlag).
+				SetParallelism(parallelismFlag).
+				SetSortByName(sortByNameFlag).
+				SetFailFast(failFastFlag).
+				SetKubeOpenShiftVersions(kubeOpenShiftVersionsFlag).
 				Build()
 
 			if err != nil {
 				return err
 			}
 
-			result, err := verifier.Verify(args[0])
+			// interrupting the verification cancels the checks in progress, which still clean up the cluster
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			result, err := verifier.Verify(ctx, args[0])
 			if err != nil {
 				return err
 			}
@@ -195,6 +216,10 @@ func NewVerifyCmd(config *viper.Viper) *cobra.Command {
 
 	cmd.Flags().StringSliceVarP(&verifyOpts.ValueFiles, "set-values", "f", nil, "specify application and check configuration values in a YAML file or a URL (can specify multiple)")
 	cmd.Flags().StringVarP(&openshiftVersionFlag, "openshift-version", "V", "", "version of OpenShift used in the cluster")
+	cmd.Flags().IntVarP(&parallelismFlag, "parallelism", "p", runtime.NumCPU(), "maximum number of checks running concurrently, checks installing the chart always run alone")
+	cmd.Flags().BoolVar(&failFastFlag, "fail-fast", false, "stop at the first check returning an error rather than reporting it with an ERROR outcome")
+	cmd.Flags().BoolVar(&sortByNameFlag, "sort-by-name", false, "run and report the checks sorted by name rather than in the order of the profile")
+	cmd.Flags().StringVar(&kubeOpenShiftVersionsFlag, "kube-openshift-versions", "", "file replacing the bundled Kubernetes-OpenShift versions, also set with --set kubeOpenShiftVersions=<file>")
 	cmd.Flags().BoolVarP(&outputLogs, "log-output", "l", false, "output logs after report (default: false) ")
 
 	return cmd
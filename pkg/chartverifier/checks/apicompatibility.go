@@ -0,0 +1,218 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/sprig"
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	APICompatibilityVersionConfigName string = "version"
+)
+
+// apiDictionariesFS contains the APIs served by default by each OpenShift release, see P0001.
+//
+//go:embed apis/*.yaml
+var apiDictionariesFS embed.FS
+
+// apiDictionary contains the group versions and kinds served by default by an OpenShift release.
+type apiDictionary struct {
+	OpenShiftVersion string             `yaml:"openshiftVersion"`
+	KubeVersion      string             `yaml:"kubeVersion"`
+	APIs             []apiDictionaryGVK `yaml:"apis"`
+}
+
+type apiDictionaryGVK struct {
+	GroupVersion string   `yaml:"groupVersion"`
+	Kinds        []string `yaml:"kinds"`
+}
+
+// serves returns whether the given group version and kind is served by the release.
+func (d *apiDictionary) serves(groupVersion, kind string) bool {
+	for _, api := range d.APIs {
+		if api.GroupVersion != groupVersion {
+			continue
+		}
+		for _, k := range api.Kinds {
+			if k == kind {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getAPIDictionaries returns the bundled API dictionaries sorted by OpenShift version.
+func getAPIDictionaries() ([]*apiDictionary, error) {
+	entries, err := apiDictionariesFS.ReadDir("apis")
+	if err != nil {
+		return nil, err
+	}
+
+	var dictionaries []*apiDictionary
+	for _, entry := range entries {
+		data, err := apiDictionariesFS.ReadFile(path.Join("apis", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		dictionary := &apiDictionary{}
+		if err := yaml.Unmarshal(data, dictionary); err != nil {
+			return nil, fmt.Errorf("reading API dictionary %s: %w", entry.Name(), err)
+		}
+		dictionaries = append(dictionaries, dictionary)
+	}
+
+	sort.Slice(dictionaries, func(i, j int) bool {
+		return semver.Compare("v"+dictionaries[i].OpenShiftVersion, "v"+dictionaries[j].OpenShiftVersion) < 0
+	})
+
+	return dictionaries, nil
+}
+
+// getTargetAPIDictionaries selects the dictionaries for the OpenShift versions the chart should be verified against.
+//
+// Versions given through configuration take precedence, for example "4.6,4.7" or "openshift-4.6,openshift-4.7".
+// Otherwise every release matching the chart's kubeVersion is selected, and the latest release if the chart has no
+// kubeVersion or none of the releases match.
+func getTargetAPIDictionaries(configVersions string, kubeVersionRange string) ([]*apiDictionary, error) {
+	dictionaries, err := getAPIDictionaries()
+	if err != nil {
+		return nil, err
+	}
+	if len(dictionaries) == 0 {
+		return nil, fmt.Errorf("%s : no API dictionaries available", APICompatibilityFailed)
+	}
+
+	var targets []*apiDictionary
+
+	if len(configVersions) > 0 {
+		for _, version := range strings.Split(configVersions, ",") {
+			version = strings.TrimPrefix(strings.TrimSpace(version), "openshift-")
+			found := false
+			for _, dictionary := range dictionaries {
+				if dictionary.OpenShiftVersion == version {
+					targets = append(targets, dictionary)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("%s : OpenShift version %s is not supported", APICompatibilityFailed, version)
+			}
+		}
+		return targets, nil
+	}
+
+	if len(kubeVersionRange) > 0 {
+		semverCompare := sprig.GenericFuncMap()["semverCompare"].(func(string, string) (bool, error))
+		for _, dictionary := range dictionaries {
+			if match, err := semverCompare(kubeVersionRange, dictionary.KubeVersion); err == nil && match {
+				targets = append(targets, dictionary)
+			}
+		}
+	}
+
+	if len(targets) == 0 {
+		targets = append(targets, dictionaries[len(dictionaries)-1])
+	}
+
+	return targets, nil
+}
+
+// APICompatibility verifies the group, version and kind of every resource rendered by the chart is served by default
+// by the targeted OpenShift releases. Kinds defined by the chart's own CRDs are considered served.
+func APICompatibility(opts *CheckOptions) (Result, error) {
+	c, _, err := LoadChartFromURI(opts.URI)
+	if err != nil {
+		return Result{}, err
+	}
+
+	dictionaries, err := getTargetAPIDictionaries(opts.ViperConfig.GetString(APICompatibilityVersionConfigName), c.Metadata.KubeVersion)
+	if err != nil {
+		return NewResult(false, err.Error()), nil
+	}
+
+	objects, err := getRenderedObjects(opts.URI, opts.Values)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s : Failed to render chart : %v", APICompatibilityFailed, err)), nil
+	}
+
+	chartAPIs := make(map[string]bool)
+	for _, object := range objects {
+		for _, gvk := range getCRDGroupVersionKinds(object) {
+			chartAPIs[gvk] = true
+		}
+	}
+
+	r := NewResult(true, "")
+	for _, dictionary := range dictionaries {
+		reported := make(map[string]bool)
+		for _, object := range objects {
+			gvk := fmt.Sprintf("%s/%s", object.GetAPIVersion(), object.GetKind())
+			if chartAPIs[gvk] || reported[gvk] || dictionary.serves(object.GetAPIVersion(), object.GetKind()) {
+				continue
+			}
+			reported[gvk] = true
+			r.AddFinding(newObjectFinding(ErrorFindingSeverity, fmt.Sprintf("%s %s : %s", APINotServed, dictionary.OpenShiftVersion, gvk), object,
+				fmt.Sprintf("use an API version served by OpenShift %s, or narrow the kubeVersion of the chart", dictionary.OpenShiftVersion)))
+		}
+	}
+
+	if r.Ok {
+		r.SetResult(true, APIsCompatible)
+	}
+
+	return r, nil
+}
+
+// getCRDGroupVersionKinds returns the group versions and kinds defined by the given object when it is a CRD.
+func getCRDGroupVersionKinds(object renderedObject) []string {
+	if object.GetKind() != "CustomResourceDefinition" {
+		return nil
+	}
+
+	group, _, _ := unstructured.NestedString(object.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(object.Object, "spec", "names", "kind")
+
+	var versions []string
+	if version, found, _ := unstructured.NestedString(object.Object, "spec", "version"); found {
+		versions = append(versions, version)
+	}
+	specVersions, _, _ := unstructured.NestedSlice(object.Object, "spec", "versions")
+	for _, v := range specVersions {
+		if version, ok := v.(map[string]interface{}); ok {
+			if name, ok := version["name"].(string); ok {
+				versions = append(versions, name)
+			}
+		}
+	}
+
+	var gvks []string
+	for _, version := range versions {
+		gvks = append(gvks, fmt.Sprintf("%s/%s/%s", group, version, kind))
+	}
+	return gvks
+}
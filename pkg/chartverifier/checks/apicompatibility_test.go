@@ -0,0 +1,96 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+func TestAPICompatibility(t *testing.T) {
+	type testCase struct {
+		description string
+		uri         string
+		version     string
+		reason      string
+	}
+
+	positiveTestCases := []testCase{
+		{description: "chart with served APIs only", uri: "chart-0.1.0-v3.valid.tgz"},
+		{description: "chart with removed APIs targeting an older release", uri: "chart-0.1.0-v3.removed-apis.tgz", version: "openshift-4.8"},
+	}
+
+	for _, tc := range positiveTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			config.Set(APICompatibilityVersionConfigName, tc.version)
+			r, err := APICompatibility(&CheckOptions{URI: tc.uri, ViperConfig: config, HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.True(t, r.Ok)
+			require.Equal(t, APIsCompatible, r.Reason)
+		})
+	}
+
+	negativeTestCases := []testCase{
+		{
+			description: "chart with removed APIs targeting its kubeVersion",
+			uri:         "chart-0.1.0-v3.removed-apis.tgz",
+			reason:      APINotServed + " 4.9 : extensions/v1beta1/Ingress",
+		},
+		{
+			description: "chart with removed APIs targeting several releases",
+			uri:         "chart-0.1.0-v3.removed-apis.tgz",
+			version:     "4.8,4.9",
+			reason:      APINotServed + " 4.9 : extensions/v1beta1/Ingress",
+		},
+		{
+			description: "unknown OpenShift release",
+			uri:         "chart-0.1.0-v3.valid.tgz",
+			version:     "3.11",
+			reason:      APICompatibilityFailed + " : OpenShift version 3.11 is not supported",
+		},
+	}
+
+	for _, tc := range negativeTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			config.Set(APICompatibilityVersionConfigName, tc.version)
+			r, err := APICompatibility(&CheckOptions{URI: tc.uri, ViperConfig: config, HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.False(t, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+}
+
+func TestAPIDictionaries(t *testing.T) {
+	dictionaries, err := getAPIDictionaries()
+	require.NoError(t, err)
+	require.NotEmpty(t, dictionaries)
+
+	for _, dictionary := range dictionaries {
+		require.NotEmpty(t, dictionary.OpenShiftVersion)
+		require.NotEmpty(t, dictionary.KubeVersion)
+		require.True(t, dictionary.serves("v1", "Pod"), "OpenShift %s should serve v1/Pod", dictionary.OpenShiftVersion)
+		require.True(t, dictionary.serves("route.openshift.io/v1", "Route"), "OpenShift %s should serve route.openshift.io/v1/Route", dictionary.OpenShiftVersion)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// bestPracticesKinds contains the workload kinds verified for best practices, and whether their containers are
+// expected to run until stopped and so to set probes.
+var bestPracticesKinds = map[string]bool{
+	"Deployment":  true,
+	"StatefulSet": true,
+	"DaemonSet":   true,
+	"Job":         false,
+	"CronJob":     false,
+}
+
+// getMissingResourcesAndProbes returns the resources and probes a container does not set.
+func getMissingResourcesAndProbes(container map[string]interface{}, probes bool) []string {
+	var missing []string
+
+	for _, resource := range []struct{ field, name string }{
+		{"requests", "request"},
+		{"limits", "limit"},
+	} {
+		for _, kind := range []string{"cpu", "memory"} {
+			if _, found, _ := unstructured.NestedFieldNoCopy(container, "resources", resource.field, kind); !found {
+				missing = append(missing, fmt.Sprintf("%s %s", kind, resource.name))
+			}
+		}
+	}
+
+	if probes {
+		for _, probe := range []struct{ field, name string }{
+			{"livenessProbe", "liveness probe"},
+			{"readinessProbe", "readiness probe"},
+		} {
+			if _, found, _ := unstructured.NestedMap(container, probe.field); !found {
+				missing = append(missing, probe.name)
+			}
+		}
+	}
+
+	return missing
+}
+
+// ContainersHaveResourcesAndProbes verifies the containers of the workloads rendered by the chart set CPU and memory
+// requests and limits, and liveness and readiness probes for workloads other than jobs. Containers always pulling an
+// image pinned by digest are also reported.
+func ContainersHaveResourcesAndProbes(opts *CheckOptions) (Result, error) {
+	_, _, err := LoadChartFromURI(opts.URI)
+	if err != nil {
+		return Result{}, err
+	}
+
+	objects, err := getRenderedObjects(opts.URI, opts.Values)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s : Failed to render chart : %v", ResourcesAndProbesCheckFailed, err)), nil
+	}
+
+	r := NewResult(true, "")
+	for _, object := range objects {
+		probes, ok := bestPracticesKinds[object.GetKind()]
+		if !ok {
+			continue
+		}
+		podSpec, found, _ := unstructured.NestedMap(object.Object, podSpecPaths[object.GetKind()]...)
+		if !found {
+			continue
+		}
+		workload := fmt.Sprintf("%s/%s (%s)", object.GetKind(), object.GetName(), object.Source)
+
+		for _, container := range nestedMaps(podSpec, "containers") {
+			name := nestedName(container, "name")
+			if missing := getMissingResourcesAndProbes(container, probes); len(missing) > 0 {
+				r.AddFinding(newObjectFinding(ErrorFindingSeverity, fmt.Sprintf("%s : %s : container %s : %s", ResourcesOrProbesMissing, workload, name, strings.Join(missing, ", ")), object,
+					fmt.Sprintf("set %s for container %s", strings.Join(missing, ", "), name)))
+			}
+			image := nestedName(container, "image")
+			if nestedName(container, "imagePullPolicy") == "Always" && len(parseImageReference(image).Sha) > 0 {
+				r.AddFinding(newObjectFinding(ErrorFindingSeverity, fmt.Sprintf("%s : %s : container %s : %s", PinnedImageAlwaysPulled, workload, name, image), object,
+					fmt.Sprintf("set imagePullPolicy to IfNotPresent for container %s", name)))
+			}
+		}
+	}
+
+	if r.Ok {
+		r.SetResult(true, ResourcesAndProbesSet)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+func TestContainersHaveResourcesAndProbes(t *testing.T) {
+	type testCase struct {
+		description string
+		uri         string
+		values      map[string]interface{}
+		reason      string
+	}
+
+	resources := map[string]interface{}{
+		"requests": map[string]interface{}{"cpu": "100m", "memory": "64Mi"},
+		"limits":   map[string]interface{}{"cpu": "500m", "memory": "256Mi"},
+	}
+
+	positiveTestCases := []testCase{
+		{
+			description: "resources set through values",
+			uri:         "chart-0.1.0-v3.valid.tgz",
+			values:      map[string]interface{}{"resources": resources},
+		},
+		{
+			description: "jobs without probes",
+			uri:         "chart-0.1.0-v3.workloads.tgz",
+			values: map[string]interface{}{
+				"resources": resources,
+				"backup":    map[string]interface{}{"pullPolicy": "IfNotPresent", "resources": resources},
+			},
+		},
+	}
+
+	for _, tc := range positiveTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r, err := ContainersHaveResourcesAndProbes(&CheckOptions{URI: tc.uri, Values: tc.values, ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.True(t, r.Ok)
+			require.Equal(t, ResourcesAndProbesSet, r.Reason)
+		})
+	}
+
+	negativeTestCases := []testCase{
+		{
+			description: "resources not set",
+			uri:         "chart-0.1.0-v3.valid.tgz",
+			reason:      ResourcesOrProbesMissing + " : Deployment/testRelease-chart (chart/templates/deployment.yaml) : container chart : cpu request, memory request, cpu limit, memory limit",
+		},
+		{
+			description: "partial resources set through values",
+			uri:         "chart-0.1.0-v3.valid.tgz",
+			values:      map[string]interface{}{"resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": "100m"}}},
+			reason:      ResourcesOrProbesMissing + " : Deployment/testRelease-chart (chart/templates/deployment.yaml) : container chart : memory request, cpu limit, memory limit",
+		},
+		{
+			description: "pinned image always pulled",
+			uri:         "chart-0.1.0-v3.workloads.tgz",
+			values:      map[string]interface{}{"resources": resources},
+			reason: ResourcesOrProbesMissing + " : CronJob/testRelease-chart-backup (chart/templates/cronjob.yaml) : container backup : cpu request, memory request, cpu limit, memory limit\n" +
+				PinnedImageAlwaysPulled + " : CronJob/testRelease-chart-backup (chart/templates/cronjob.yaml) : container backup : quay.io/example/backup@sha256:0a7b3c6e7f9c8a5e4d3c2b1a09f8e7d6c5b4a3928171605f4e3d2c1b0a998877",
+		},
+	}
+
+	for _, tc := range negativeTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r, err := ContainersHaveResourcesAndProbes(&CheckOptions{URI: tc.uri, Values: tc.values, ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.False(t, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+			require.Len(t, r.Findings, len(strings.Split(tc.reason, "\n")))
+			for _, finding := range r.Findings {
+				require.Equal(t, ErrorFindingSeverity, finding.Severity)
+				require.NotEmpty(t, finding.Kind)
+				require.NotEmpty(t, finding.Name)
+				require.NotEmpty(t, finding.Path)
+				require.NotEmpty(t, finding.Remediation)
+			}
+		})
+	}
+}
@@ -2,10 +2,15 @@ package checks
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/Masterminds/semver"
 	"github.com/helm/chart-testing/v3/pkg/chart"
@@ -14,37 +19,54 @@ import (
 	"github.com/imdario/mergo"
 	"github.com/redhat-certification/chart-verifier/pkg/tool"
 	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli"
 	helmcli "helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/storage/driver"
 )
 
 const (
 	ReleaseConfigString string = "release"
+	// BestEffortConfigString is the configuration key to install and test the chart with every values file of its ci
+	// directory, rather than stopping at the first failure.
+	BestEffortConfigString string = "bestEffort"
+	// ArtifactsDirConfigString is the configuration key of the directory the diagnostics of the failed releases are
+	// written to.
+	ArtifactsDirConfigString string = "artifactsDir"
+	// VerifyUninstallConfigString is the configuration key to verify that no object carrying the release label is left
+	// once a release is uninstalled.
+	VerifyUninstallConfigString string = "verifyUninstall"
+
+	// diagnosticsFindingLogLines is the number of lines of each container log added to the findings of the check.
+	diagnosticsFindingLogLines = 20
+
+	// cleanupTimeout bounds the cleanup of a release, which runs even when the check has been cancelled.
+	cleanupTimeout = 5 * time.Minute
+	// leftoversTimeout bounds the wait for the objects of an uninstalled release to be deleted.
+	leftoversTimeout = 30 * time.Second
+	// leftoversPollInterval is the interval between two listings of the objects of an uninstalled release.
+	leftoversPollInterval = 2 * time.Second
 )
 
 // Versioner provides OpenShift version
-type Versioner func(envSettings *cli.EnvSettings) (string, error)
+type Versioner func(ctx context.Context, envSettings *cli.EnvSettings, versions tool.KubeOpenShiftVersions) (string, error)
 
-func getVersion(envSettings *cli.EnvSettings) (string, error) {
+func getVersion(ctx context.Context, envSettings *cli.EnvSettings, versions tool.KubeOpenShiftVersions) (string, error) {
 	kubeConfig := tool.GetClientConfig(envSettings)
 	kubectl, err := tool.NewKubectl(kubeConfig)
 	if err != nil {
 		return "", err
 	}
 
-	serverVersion, err := kubectl.GetServerVersion()
+	osVersion, err := kubectl.GetOpenShiftVersion(ctx, versions)
 	if err != nil {
 		return "", err
 	}
-
-	// Relying on Kubernetes version can be replaced after fixing this issue:
-	// https://bugzilla.redhat.com/show_bug.cgi?id=1850656
-	kubeVersion := fmt.Sprintf("%s.%s", serverVersion.Major, serverVersion.Minor)
-	osVersion, ok := tool.GetKubeOpenShiftVersionMap()[kubeVersion]
-	if !ok {
-		return "", fmt.Errorf("internal error: %q not found in Kubernetes-OpenShift version map", kubeVersion)
+	if status, ok := versions.GetOpenShiftVersionStatus(osVersion); !ok {
+		tool.LogWarning(fmt.Sprintf("OpenShift version %s is not in the Kubernetes-OpenShift versions", osVersion))
+	} else if status == tool.EndOfLifeStatus {
+		tool.LogWarning(fmt.Sprintf("OpenShift version %s has reached its end of life", osVersion))
 	}
-
 	return osVersion, nil
 }
 
@@ -104,6 +126,7 @@ func ChartTesting(opts *CheckOptions) (Result, error) {
 
 	tool.LogInfo("Start chart install and test check")
 
+	ctx := checkContext(opts)
 	cfg := buildChartTestingConfiguration(opts)
 	helm, err := tool.NewHelm(opts.HelmEnvSettings, opts.Values)
 	if err != nil {
@@ -115,7 +138,15 @@ func ChartTesting(opts *CheckOptions) (Result, error) {
 	kubectl, err := tool.NewKubectl(kubeConfig)
 	if err != nil {
 		tool.LogError("End chart install and test check with NewKubectl error")
-		return NewResult(false, err.Error()), nil
+		return NewSkippedResult(fmt.Sprintf("%s : %v", NoClusterAvailable, err)), nil
+	}
+
+	if _, err := kubectl.GetServerVersion(ctx); err != nil {
+		tool.LogError("End chart install and test check with GetServerVersion error")
+		if ctx.Err() != nil {
+			return Result{}, err
+		}
+		return NewSkippedResult(fmt.Sprintf("%s : %v", NoClusterAvailable, err)), nil
 	}
 
 	_, path, err := LoadChartFromURI(opts.URI)
@@ -135,13 +166,17 @@ func ChartTesting(opts *CheckOptions) (Result, error) {
 		tool.LogInfo(fmt.Sprintf("User specifed release: %s", configRelease))
 	}
 
+	bestEffort := opts.ViperConfig.GetBool(BestEffortConfigString)
+	verifyUninstall := opts.ViperConfig.GetBool(VerifyUninstallConfigString)
+	var results []valuesFileResult
 	if cfg.Upgrade {
-		oldChrt, err := getChartPreviousVersion(chrt)
+		oldChrt, err := getChartPreviousVersion(ctx, chrt,
+			opts.ViperConfig.GetString(PreviousChartUriConfigString),
+			opts.ViperConfig.GetString(PreviousChartRepositoryConfigString))
 		if err != nil {
-			tool.LogError("End chart install and test check with getChartPreviousVersion error")
-			return NewResult(
-					false,
-					fmt.Sprintf("skipping upgrade test of '%s' because no previous chart is available", chrt.Yaml().Name)),
+			tool.LogError(fmt.Sprintf("End chart install and test check with getChartPreviousVersion error: %v", err))
+			return NewSkippedResult(
+					fmt.Sprintf("skipping upgrade test of '%s' because no previous chart is available : %v", chrt.Yaml().Name, err)),
 				nil
 		}
 		breakingChangeAllowed, err := util.BreakingChangeAllowed(oldChrt.Yaml().Version, chrt.Yaml().Version)
@@ -155,147 +190,425 @@ func ChartTesting(opts *CheckOptions) (Result, error) {
 			tool.LogError(fmt.Sprintf("End chart install and test check with BreakingChangeAllowed error: %v", err))
 			return NewResult(false, err.Error()), nil
 		}
-		result := upgradeAndTestChart(cfg, oldChrt, chrt, helm, kubectl, configRelease)
-
-		if result.Error != nil {
-			tool.LogError(fmt.Sprintf("End chart install and test check with upgradeAndTestChart error: %v", result.Error))
-			return NewResult(false, result.Error.Error()), nil
-		}
+		results = upgradeAndTestChart(ctx, cfg, oldChrt, chrt, helm, kubectl, configRelease, bestEffort, verifyUninstall)
 	} else {
-		result := installAndTestChartRelease(cfg, chrt, helm, kubectl, opts.Values, configRelease)
-		if result.Error != nil {
-			tool.LogError(fmt.Sprintf("End chart install and test check with installAndTestChartRelease error: %v", result.Error))
-			return NewResult(false, result.Error.Error()), nil
-		}
+		results = installAndTestChartRelease(ctx, cfg, chrt, helm, kubectl, opts.Values, configRelease, bestEffort, verifyUninstall)
+	}
+
+	r := NewResult(true, ChartTestingSuccess)
+	if err := getValuesFilesError(results); err != nil && ctx.Err() != nil {
+		tool.LogError(fmt.Sprintf("End chart install and test check interrupted: %v", err))
+		return Result{}, fmt.Errorf("%w : %v", ctx.Err(), err)
+	} else if bestEffort {
+		r = getValuesFilesResult(results)
+	} else if err != nil {
+		r = NewResult(false, err.Error())
+	}
+	attachDiagnostics(&r, results, opts.ViperConfig.GetString(ArtifactsDirConfigString))
+	attachLeftovers(&r, results)
+	if !r.Ok {
+		tool.LogError(fmt.Sprintf("End chart install and test check with error: %s", r.Reason))
+		return r, nil
 	}
 
-	if versionError := setOCVersion(opts.AnnotationHolder, opts.HelmEnvSettings, getVersion); versionError != nil {
+	if versionError := setOCVersion(ctx, opts.AnnotationHolder, opts.HelmEnvSettings, kubeOpenShiftVersions(opts), getVersion); versionError != nil {
 		if versionError != nil {
 			tool.LogWarning(fmt.Sprintf("End chart install and test check with version error: %v", versionError))
 		}
-		return NewResult(false, versionError.Error()), nil
+		if ctx.Err() != nil {
+			return Result{}, fmt.Errorf("%w : %v", ctx.Err(), versionError)
+		}
+		return NewErrorResult(versionError.Error()), nil
 	}
 
 	tool.LogInfo("End chart install and test check")
-	return NewResult(true, ChartTestingSuccess), nil
+	return r, nil
 }
 
 // generateInstallConfig extracts required information to install a
 // release and builds a clenup function to be used after tests are
-// executed.
+// executed. The cleanup does not use the context of the check, so it
+// still runs when the check has been cancelled.
 func generateInstallConfig(
 	cfg config.Configuration,
 	chrt *chart.Chart,
 	helm *tool.Helm,
 	kubectl *tool.Kubectl,
 	configRelease string,
-) (namespace, release, releaseSelector string, cleanup func()) {
+	verifyUninstall bool,
+) (namespace, release, releaseSelector string, cleanup func() ([]tool.ReleaseObject, error)) {
 	release = configRelease
+	deleteNamespace := false
 	if cfg.Namespace != "" {
 		namespace = cfg.Namespace
 		if len(release) == 0 {
 			release, _ = chrt.CreateInstallParams(cfg.BuildId)
 		}
 		releaseSelector = fmt.Sprintf("%s=%s", cfg.ReleaseLabel, release)
-		cleanup = func() {
-			helm.Uninstall(namespace, release)
-		}
 	} else {
 		if len(release) == 0 {
 			release, namespace = chrt.CreateInstallParams(cfg.BuildId)
 		} else {
 			_, namespace = chrt.CreateInstallParams(cfg.BuildId)
 		}
-		cleanup = func() {
-			helm.Uninstall(namespace, release)
-			kubectl.DeleteNamespace(context.TODO(), namespace)
-		}
+		deleteNamespace = true
+	}
+	cleanup = func() ([]tool.ReleaseObject, error) {
+		releaseLabelSelector := fmt.Sprintf("%s=%s", cfg.ReleaseLabel, release)
+		return cleanupRelease(helm, kubectl, namespace, release, releaseLabelSelector, deleteNamespace, verifyUninstall)
 	}
 	return
 }
 
-// testRelease tests a release.
-func testRelease(
+// cleanupRelease uninstalls a release and deletes the namespace created for it, if any. When verifyUninstall is set,
+// the objects still carrying the release label once the release is uninstalled are returned, they are looked up before
+// the namespace is deleted along with its objects.
+func cleanupRelease(
 	helm *tool.Helm,
 	kubectl *tool.Kubectl,
-	release, namespace, releaseSelector string,
-	cleanupHelmTests bool,
-) error {
-	if err := kubectl.WaitForDeployments(context.TODO(), namespace, releaseSelector); err != nil {
-		return err
+	namespace, release, releaseLabelSelector string,
+	deleteNamespace, verifyUninstall bool,
+) ([]tool.ReleaseObject, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
+	var errs []string
+	var leftovers []tool.ReleaseObject
+	// the release does not exist when its installation failed before anything was created
+	if err := helm.Uninstall(ctx, namespace, release); err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		errs = append(errs, fmt.Sprintf("uninstalling release %s : %v", release, err))
+	} else if verifyUninstall {
+		leftovers = getLeftovers(ctx, kubectl, namespace, releaseLabelSelector)
+	}
+	if deleteNamespace {
+		if err := kubectl.DeleteNamespace(ctx, namespace); err != nil {
+			errs = append(errs, fmt.Sprintf("deleting namespace %s : %v", namespace, err))
+		}
 	}
-	if err := helm.Test(namespace, release); err != nil {
-		return err
+
+	if len(errs) > 0 {
+		return leftovers, errors.New(strings.Join(errs, ", "))
+	}
+	return leftovers, nil
+}
+
+// getLeftovers waits for the objects matching the selector of an uninstalled release to be deleted, returning those
+// still left after leftoversTimeout. Objects such as pods are deleted some time after the release is uninstalled.
+func getLeftovers(ctx context.Context, kubectl *tool.Kubectl, namespace, releaseLabelSelector string) []tool.ReleaseObject {
+	ctx, cancel := context.WithTimeout(ctx, leftoversTimeout)
+	defer cancel()
+
+	var leftovers []tool.ReleaseObject
+	for {
+		objects, err := kubectl.ListReleaseObjects(ctx, namespace, releaseLabelSelector)
+		if err != nil {
+			if ctx.Err() == nil {
+				tool.LogWarning(fmt.Sprintf("Error verifying the uninstall of %s: %v", releaseLabelSelector, err))
+			}
+			return leftovers
+		}
+		if leftovers = objects; len(leftovers) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return leftovers
+		case <-time.After(leftoversPollInterval):
+		}
+	}
+}
+
+// testStep is a step of the installation and test of a release, e.g. install, wait, test or uninstall.
+type testStep struct {
+	name     string
+	duration time.Duration
+	err      error
+}
+
+// valuesFileResult is the result of the installation and test of a release with a values file of the chart.
+type valuesFileResult struct {
+	valuesFile string
+	steps      []testStep
+	// diagnostics of the release, collected when a step failed.
+	diagnostics *tool.Diagnostics
+	// leftovers are the objects of the release left once it was uninstalled, when the uninstall is verified.
+	leftovers []tool.ReleaseObject
+}
+
+// run runs a step and records its duration and error.
+func (r *valuesFileResult) run(name string, step func() error) error {
+	start := time.Now()
+	err := step()
+	r.steps = append(r.steps, testStep{name: name, duration: time.Since(start), err: err})
+	return err
+}
+
+// Err returns the error of the first failed step, if any.
+func (r *valuesFileResult) Err() error {
+	for _, step := range r.steps {
+		if step.err != nil {
+			return step.err
+		}
 	}
 	return nil
 }
 
-// getChartPreviousVersion attemtps to retrieve the previous version
-// of the given chart.
-func getChartPreviousVersion(chrt *chart.Chart) (*chart.Chart, error) {
-	// TODO: decide which sources do we consider when searching for a
-	//       previous version's candidate
-	return chrt, nil
+// String summarizes the steps and their durations, followed by the error of the failed step, e.g.
+// "ci/a-values.yaml : install 10.2s, wait 2.5s (failed), uninstall 1.1s : 1 replicas unavailable".
+func (r *valuesFileResult) String() string {
+	var steps []string
+	for _, step := range r.steps {
+		summary := fmt.Sprintf("%s %s", step.name, step.duration.Round(time.Millisecond))
+		if step.err != nil {
+			summary += " (failed)"
+		}
+		steps = append(steps, summary)
+	}
+	summary := fmt.Sprintf("%s : %s", r.valuesFile, strings.Join(steps, ", "))
+	if err := r.Err(); err != nil {
+		summary += " : " + err.Error()
+	}
+	return summary
 }
 
-// upgradeAndTestChart performs the installation of the given oldChrt,
-// and attempts to perform an upgrade from that state.
-func upgradeAndTestChart(
-	cfg config.Configuration,
-	oldChrt, chrt *chart.Chart,
-	helm *tool.Helm,
-	kubectl *tool.Kubectl,
-	configRelease string,
-) chart.TestResult {
+// getValuesFilesError returns the error of the first values file which failed, if any.
+func getValuesFilesError(results []valuesFileResult) error {
+	for _, result := range results {
+		if err := result.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	// result contains the test result; please notice that each values
-	// file in the chart's 'ci' folder will be installed and tested
-	// and the first failure makes the test fail.
-	result := chart.TestResult{Chart: chrt}
+// getValuesFilesResult returns a result with a finding for each values file, saying whether it passed.
+func getValuesFilesResult(results []valuesFileResult) Result {
+	r := NewResult(true, "")
+	for _, result := range results {
+		finding := Finding{Severity: InfoFindingSeverity, Message: fmt.Sprintf("%s : %s", ChartTestingSuccess, result.String()), Path: result.valuesFile}
+		if result.Err() != nil {
+			finding.Severity = ErrorFindingSeverity
+			finding.Message = fmt.Sprintf("%s : %s", ChartTestingFailure, result.String())
+		}
+		r.AddFinding(finding)
+	}
+	return r
+}
 
-	valuesFiles := oldChrt.ValuesFilePathsForCI()
-	if len(valuesFiles) == 0 {
-		valuesFiles = append(valuesFiles, "")
+// collectDiagnostics collects the diagnostics of a release when a step failed, it must run before the release is
+// cleaned up. The diagnostics are collected even when the check has been cancelled.
+func collectDiagnostics(result *valuesFileResult, kubectl *tool.Kubectl, namespace, release, releaseSelector string) {
+	if result.Err() == nil {
+		return
 	}
-	for _, valuesFile := range valuesFiles {
-		if valuesFile != "" {
-			if cfg.SkipMissingValues && !chrt.HasCIValuesFile(valuesFile) {
-				// TODO: do not assume STDOUT here; instead a writer
-				//       should be given to be written to.
-				fmt.Printf("Upgrade testing for values file '%s' skipped because a corresponding values file was not found in %s/ci", valuesFile, chrt.Path())
-				continue
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	diagnostics, err := kubectl.GetDiagnostics(ctx, namespace, release, releaseSelector)
+	if err != nil {
+		tool.LogWarning(fmt.Sprintf("Error collecting diagnostics of release %s: %v", release, err))
+		return
+	}
+	result.diagnostics = diagnostics
+}
+
+// getDiagnosticsFindings returns info findings describing the diagnostics of a release, with the last lines of the
+// container logs.
+func getDiagnosticsFindings(result valuesFileResult) []Finding {
+	var findings []Finding
+	for _, pod := range result.diagnostics.Pods {
+		findings = append(findings, Finding{Severity: InfoFindingSeverity, Message: fmt.Sprintf("Pod status : %s", pod.Status),
+			Path: result.valuesFile, Kind: "Pod", Name: pod.Name})
+		var containers []string
+		for container := range pod.Logs {
+			containers = append(containers, container)
+		}
+		sort.Strings(containers)
+		for _, container := range containers {
+			lines := strings.Split(strings.TrimRight(pod.Logs[container], "\n"), "\n")
+			if len(lines) > diagnosticsFindingLogLines {
+				lines = lines[len(lines)-diagnosticsFindingLogLines:]
+			}
+			findings = append(findings, Finding{Severity: InfoFindingSeverity,
+				Message: fmt.Sprintf("Container logs : %s :\n%s", container, strings.Join(lines, "\n")),
+				Path:    result.valuesFile, Kind: "Pod", Name: pod.Name})
+		}
+	}
+	for _, deployment := range result.diagnostics.Deployments {
+		findings = append(findings, Finding{Severity: InfoFindingSeverity, Message: fmt.Sprintf("Deployment status : %s", deployment),
+			Path: result.valuesFile, Kind: "Deployment"})
+	}
+	for _, event := range result.diagnostics.Events {
+		findings = append(findings, Finding{Severity: InfoFindingSeverity, Message: fmt.Sprintf("Event : %s", event),
+			Path: result.valuesFile, Kind: "Event"})
+	}
+	return findings
+}
+
+// attachDiagnostics adds the diagnostics of the failed releases to the findings of the result, without adding them to
+// its reason, and writes them in a directory per values file of the artifacts directory, if set.
+func attachDiagnostics(r *Result, results []valuesFileResult, artifactsDir string) {
+	for _, result := range results {
+		if result.diagnostics == nil {
+			continue
+		}
+		r.Findings = append(r.Findings, getDiagnosticsFindings(result)...)
+		if artifactsDir != "" {
+			dir := filepath.Join(artifactsDir, strings.ReplaceAll(result.valuesFile, "/", "_"))
+			if err := result.diagnostics.WriteFiles(dir); err != nil {
+				tool.LogWarning(fmt.Sprintf("Error writing diagnostics to %s: %v", dir, err))
 			}
 		}
+	}
+}
 
-		// Use anonymous function. Otherwise deferred calls would pile up
-		// and be executed in reverse order after the loop.
-		fun := func() error {
-			namespace, release, releaseSelector, cleanup := generateInstallConfig(cfg, oldChrt, helm, kubectl, configRelease)
-			defer cleanup()
+// getLeftoverRemediation returns a hint on how to have the object deleted with the release.
+func getLeftoverRemediation(object tool.ReleaseObject) string {
+	switch {
+	case object.Terminating:
+		return "The object is being deleted, check its finalizers"
+	case object.Hook:
+		return "Helm does not delete hooks with the release, set the helm.sh/hook-delete-policy annotation of the hook"
+	case object.Kind == "PersistentVolumeClaim":
+		return "Claims created from the volumeClaimTemplates of a StatefulSet are not deleted with it, document or automate their deletion"
+	default:
+		return "Make sure the object is part of the release or is deleted along with the objects creating it"
+	}
+}
 
-			// Install previous version of chart. If installation fails, ignore this release.
-			if err := helm.Install(namespace, oldChrt.Path(), release, valuesFile); err != nil {
-				return fmt.Errorf("Upgrade testing for release '%s' skipped because of previous revision installation error: %w", release, err)
+// attachLeftovers adds a finding for each object left once a release was uninstalled. Helm keeps the hooks without a
+// delete policy on purpose, such as the test pod of the chart scaffold, so they are reported as warnings, while the
+// other objects are orphans failing the check.
+func attachLeftovers(r *Result, results []valuesFileResult) {
+	for _, result := range results {
+		for _, object := range result.leftovers {
+			severity := WarningFindingSeverity
+			if !object.Hook {
+				severity = ErrorFindingSeverity
+				// the release was installed and tested successfully, but not uninstalled cleanly
+				if r.Ok && r.Reason == ChartTestingSuccess {
+					r.Reason = ""
+				}
 			}
-			if err := testRelease(helm, kubectl, release, namespace, releaseSelector, true); err != nil {
-				return fmt.Errorf("Upgrade testing for release '%s' skipped because of previous revision testing error", release)
+			state := ""
+			if object.Terminating {
+				state = " (terminating)"
 			}
+			r.AddFinding(Finding{
+				Severity:    severity,
+				Message:     fmt.Sprintf("%s : %s : %s%s", ChartTestingLeftoverObject, result.valuesFile, object, state),
+				Path:        result.valuesFile,
+				Kind:        object.Kind,
+				Name:        object.Name,
+				Remediation: getLeftoverRemediation(object),
+			})
+		}
+	}
+}
 
-			if err := helm.Upgrade(namespace, oldChrt.Path(), release); err != nil {
-				return err
+// testValuesFiles runs the test of a release for each values file, stopping at the first failure unless best effort is
+// set. The test stops anyway once the context is done.
+func testValuesFiles(
+	ctx context.Context,
+	chrt *chart.Chart,
+	valuesFiles []string,
+	bestEffort bool,
+	test func(valuesFile string, result *valuesFileResult),
+) []valuesFileResult {
+	var results []valuesFileResult
+	for _, valuesFile := range valuesFiles {
+		result := valuesFileResult{valuesFile: chartutil.ValuesfileName}
+		if valuesFile != "" {
+			if name, err := filepath.Rel(chrt.Path(), valuesFile); err == nil {
+				result.valuesFile = name
+			} else {
+				result.valuesFile = valuesFile
 			}
-
-			return testRelease(helm, kubectl, release, namespace, releaseSelector, false)
 		}
 
-		if err := fun(); err != nil {
-			result.Error = err
+		test(valuesFile, &result)
+		results = append(results, result)
+
+		if result.Err() != nil && (!bestEffort || ctx.Err() != nil) {
 			break
 		}
+	}
+	return results
+}
+
+// testRelease tests a release, waiting for its resources to be ready before running its tests. The names of the steps are
+// suffixed with the given suffix.
+func testRelease(
+	ctx context.Context,
+	result *valuesFileResult,
+	helm *tool.Helm,
+	kubectl *tool.Kubectl,
+	release, namespace, releaseSelector string,
+	stepSuffix string,
+) error {
+	if err := result.run("wait"+stepSuffix, func() error {
+		return kubectl.WaitForResources(ctx, namespace, releaseSelector)
+	}); err != nil {
+		return err
+	}
+	return result.run("test"+stepSuffix, func() error {
+		return helm.Test(ctx, namespace, release)
+	})
+}
 
+// upgradeAndTestChart performs the installation of the given oldChrt,
+// and attempts to perform an upgrade from that state.
+func upgradeAndTestChart(
+	ctx context.Context,
+	cfg config.Configuration,
+	oldChrt, chrt *chart.Chart,
+	helm *tool.Helm,
+	kubectl *tool.Kubectl,
+	configRelease string,
+	bestEffort bool,
+	verifyUninstall bool,
+) []valuesFileResult {
+
+	// each values file in the chart's 'ci' folder will be installed
+	// and tested.
+	var valuesFiles []string
+	for _, valuesFile := range oldChrt.ValuesFilePathsForCI() {
+		if cfg.SkipMissingValues && !chrt.HasCIValuesFile(valuesFile) {
+			// TODO: do not assume STDOUT here; instead a writer
+			//       should be given to be written to.
+			fmt.Printf("Upgrade testing for values file '%s' skipped because a corresponding values file was not found in %s/ci", valuesFile, chrt.Path())
+			continue
+		}
+		valuesFiles = append(valuesFiles, valuesFile)
+	}
+	if len(oldChrt.ValuesFilePathsForCI()) == 0 {
+		valuesFiles = append(valuesFiles, "")
 	}
 
-	return result
+	return testValuesFiles(ctx, oldChrt, valuesFiles, bestEffort, func(valuesFile string, result *valuesFileResult) {
+		namespace, release, releaseSelector, cleanup := generateInstallConfig(cfg, oldChrt, helm, kubectl, configRelease, verifyUninstall)
+		defer result.run("uninstall", func() error {
+			var err error
+			result.leftovers, err = cleanup()
+			return err
+		})
+		defer collectDiagnostics(result, kubectl, namespace, release, releaseSelector)
+
+		// Install and test the previous version of chart, then upgrade the release to the chart.
+		if err := result.run("install previous", func() error {
+			return helm.Install(ctx, namespace, oldChrt.Path(), release, valuesFile)
+		}); err != nil {
+			return
+		}
+		if err := testRelease(ctx, result, helm, kubectl, release, namespace, releaseSelector, " previous"); err != nil {
+			return
+		}
+		if err := result.run("upgrade", func() error {
+			return helm.Upgrade(ctx, namespace, chrt.Path(), release)
+		}); err != nil {
+			return
+		}
+		_ = testRelease(ctx, result, helm, kubectl, release, namespace, releaseSelector, "")
+	})
 }
 
 // readObjectFromYamlFile unmarshals the given filename and returns an object with its contents.
@@ -377,13 +690,16 @@ func newTempValuesFileWithOverrides(filename string, valuesOverrides map[string]
 
 // installAndTestChartRelease installs and tests a chart release.
 func installAndTestChartRelease(
+	ctx context.Context,
 	cfg config.Configuration,
 	chrt *chart.Chart,
 	helm *tool.Helm,
 	kubectl *tool.Kubectl,
 	valuesOverrides map[string]interface{},
 	configRelease string,
-) chart.TestResult {
+	bestEffort bool,
+	verifyUninstall bool,
+) []valuesFileResult {
 
 	// valuesFiles contains all the configurations that should be
 	// executed; in other words, it performs a test matrix between
@@ -395,45 +711,37 @@ func installAndTestChartRelease(
 		valuesFiles = append(valuesFiles, "")
 	}
 
-	result := chart.TestResult{Chart: chrt}
-
-	for _, valuesFile := range valuesFiles {
-
-		// Use anonymous function. Otherwise deferred calls would pile up
-		// and be executed in reverse order after the loop.
-		fun := func() error {
-
-			tmpValuesFile, tmpValuesFileCleanup, err := newTempValuesFileWithOverrides(valuesFile, valuesOverrides)
-			if err != nil {
-				// it is required this operation to succeed, otherwise there are no guarantees the values informed using
-				// `--chart-set` are propagated to the installation process, so the process breaks here.
-				return fmt.Errorf("creating temporary values file: %w", err)
-			}
-			defer tmpValuesFileCleanup()
-
-			namespace, release, releaseSelector, releaseCleanup := generateInstallConfig(cfg, chrt, helm, kubectl, configRelease)
-			defer releaseCleanup()
-
-			if err := helm.Install(namespace, chrt.Path(), release, tmpValuesFile); err != nil {
-				return err
-			}
-			return testRelease(helm, kubectl, release, namespace, releaseSelector, false)
+	return testValuesFiles(ctx, chrt, valuesFiles, bestEffort, func(valuesFile string, result *valuesFileResult) {
+		tmpValuesFile, tmpValuesFileCleanup, err := newTempValuesFileWithOverrides(valuesFile, valuesOverrides)
+		if err != nil {
+			// it is required this operation to succeed, otherwise there are no guarantees the values informed using
+			// `--chart-set` are propagated to the installation process, so the process breaks here.
+			result.steps = append(result.steps, testStep{name: "prepare", err: fmt.Errorf("creating temporary values file: %w", err)})
+			return
 		}
-
-		if err := fun(); err != nil {
-			// fail fast approach; could be changed to best effort.
-			result.Error = err
-			break
+		defer tmpValuesFileCleanup()
+
+		namespace, release, releaseSelector, releaseCleanup := generateInstallConfig(cfg, chrt, helm, kubectl, configRelease, verifyUninstall)
+		defer result.run("uninstall", func() error {
+			var err error
+			result.leftovers, err = releaseCleanup()
+			return err
+		})
+		defer collectDiagnostics(result, kubectl, namespace, release, releaseSelector)
+
+		if err := result.run("install", func() error {
+			return helm.Install(ctx, namespace, chrt.Path(), release, tmpValuesFile)
+		}); err != nil {
+			return
 		}
-	}
-
-	return result
+		_ = testRelease(ctx, result, helm, kubectl, release, namespace, releaseSelector, "")
+	})
 }
 
-func setOCVersion(holder AnnotationHolder, envSettings *helmcli.EnvSettings, versioner Versioner) error {
+func setOCVersion(ctx context.Context, holder AnnotationHolder, envSettings *helmcli.EnvSettings, versions tool.KubeOpenShiftVersions, versioner Versioner) error {
 	// kubectl.GetVersion() returns an error both in case the kubectl command can't be executed and
 	// the value for the OpenShift version key not present.
-	osVersion, getVersionErr := versioner(envSettings)
+	osVersion, getVersionErr := versioner(ctx, envSettings, versions)
 
 	// From this point on, an error is set and osVersion is empty.
 	if getVersionErr != nil && holder.GetCertifiedOpenShiftVersionFlag() != "" {
@@ -1,14 +1,18 @@
 package checks
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
+	"github.com/helm/chart-testing/v3/pkg/chart"
+	"github.com/redhat-certification/chart-verifier/pkg/tool"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 	"helm.sh/helm/v3/pkg/cli"
@@ -104,11 +108,145 @@ func TestChartTesting(t *testing.T) {
 	}
 }
 
-func getVersionError(settings *cli.EnvSettings) (string, error) {
+func TestChartTestingWithoutCluster(t *testing.T) {
+	settings := cli.New()
+	settings.KubeConfig = filepath.Join(t.TempDir(), "kubeconfig")
+
+	r, err := ChartTesting(&CheckOptions{URI: "chart-0.1.0-v3.valid.tgz", ViperConfig: viper.New(), HelmEnvSettings: settings})
+	require.NoError(t, err)
+	require.False(t, r.Ok)
+	require.Equal(t, SkippedResultStatus, r.Status)
+	require.Contains(t, r.Reason, NoClusterAvailable)
+}
+
+func TestTestValuesFiles(t *testing.T) {
+	_, chartPath, err := LoadChartFromURI("chart-0.1.0-v3.values-schema.tgz")
+	require.NoError(t, err)
+	chrt, err := chart.NewChart(chartPath)
+	require.NoError(t, err)
+	require.Len(t, chrt.ValuesFilePathsForCI(), 2)
+
+	// the release fails to become ready with ci/bad-values.yaml
+	test := func(valuesFile string, result *valuesFileResult) {
+		defer result.run("uninstall", func() error { return nil })
+		if err := result.run("install", func() error { return nil }); err != nil {
+			return
+		}
+		if err := result.run("wait", func() error {
+			if strings.HasSuffix(valuesFile, "bad-values.yaml") {
+				return errors.New("1 replicas unavailable")
+			}
+			return nil
+		}); err != nil {
+			return
+		}
+		_ = result.run("test", func() error { return nil })
+	}
+
+	t.Run("Values files should be tested until the first failure", func(t *testing.T) {
+		results := testValuesFiles(context.Background(), chrt, chrt.ValuesFilePathsForCI(), false, test)
+		require.Len(t, results, 1)
+		require.Equal(t, "ci/bad-values.yaml", results[0].valuesFile)
+		require.EqualError(t, getValuesFilesError(results), "1 replicas unavailable")
+	})
+
+	t.Run("Every values file should be tested with best effort", func(t *testing.T) {
+		results := testValuesFiles(context.Background(), chrt, chrt.ValuesFilePathsForCI(), true, test)
+		require.Len(t, results, 2)
+
+		r := getValuesFilesResult(results)
+		require.False(t, r.Ok)
+		require.Len(t, r.Findings, 2)
+
+		require.Equal(t, ErrorFindingSeverity, r.Findings[0].Severity)
+		require.Equal(t, "ci/bad-values.yaml", r.Findings[0].Path)
+		require.Regexp(t, `^Chart tests have failed : ci/bad-values.yaml : install \S+, wait \S+ \(failed\), uninstall \S+ : 1 replicas unavailable$`, r.Findings[0].Message)
+
+		require.Equal(t, InfoFindingSeverity, r.Findings[1].Severity)
+		require.Equal(t, "ci/good-values.yaml", r.Findings[1].Path)
+		require.Regexp(t, `^Chart tests have passed : ci/good-values.yaml : install \S+, wait \S+, test \S+, uninstall \S+$`, r.Findings[1].Message)
+	})
+
+	t.Run("Chart default values should be tested without values files", func(t *testing.T) {
+		results := testValuesFiles(context.Background(), chrt, []string{""}, true, test)
+		require.Len(t, results, 1)
+		require.Equal(t, "values.yaml", results[0].valuesFile)
+		require.True(t, getValuesFilesResult(results).Ok)
+	})
+}
+
+func TestAttachLeftovers(t *testing.T) {
+	leftovers := []tool.ReleaseObject{
+		{Kind: "ClusterRole", Name: "release-reader"},
+		{Kind: "PersistentVolumeClaim", Namespace: "shared", Name: "data-release-db-0"},
+		{Kind: "Pod", Namespace: "shared", Name: "release-test-connection", Hook: true},
+	}
+
+	t.Run("Objects left by a successful release should fail the check", func(t *testing.T) {
+		results := []valuesFileResult{{valuesFile: "ci/good-values.yaml", leftovers: leftovers}}
+		r := NewResult(true, ChartTestingSuccess)
+		attachLeftovers(&r, results)
+
+		require.False(t, r.Ok)
+		require.NotContains(t, r.Reason, ChartTestingSuccess)
+		require.Len(t, r.Findings, 3)
+		require.Equal(t, Finding{
+			Severity:    ErrorFindingSeverity,
+			Message:     "Object of the release left after uninstall : ci/good-values.yaml : ClusterRole release-reader",
+			Path:        "ci/good-values.yaml",
+			Kind:        "ClusterRole",
+			Name:        "release-reader",
+			Remediation: "Make sure the object is part of the release or is deleted along with the objects creating it",
+		}, r.Findings[0])
+		require.Equal(t, "Object of the release left after uninstall : ci/good-values.yaml : PersistentVolumeClaim shared/data-release-db-0", r.Findings[1].Message)
+		require.Contains(t, r.Findings[1].Remediation, "volumeClaimTemplates")
+		require.Equal(t, WarningFindingSeverity, r.Findings[2].Severity)
+		require.Contains(t, r.Findings[2].Remediation, "helm.sh/hook-delete-policy")
+	})
+
+	t.Run("Hooks left by a successful release should only warn", func(t *testing.T) {
+		results := []valuesFileResult{{valuesFile: "ci/good-values.yaml", leftovers: leftovers[2:]}}
+		r := NewResult(true, ChartTestingSuccess)
+		attachLeftovers(&r, results)
+
+		require.True(t, r.Ok)
+		require.True(t, r.IsWarning())
+		require.Contains(t, r.Reason, ChartTestingSuccess)
+		require.Equal(t, []Finding{{
+			Severity:    WarningFindingSeverity,
+			Message:     "Object of the release left after uninstall : ci/good-values.yaml : Pod shared/release-test-connection",
+			Path:        "ci/good-values.yaml",
+			Kind:        "Pod",
+			Name:        "release-test-connection",
+			Remediation: "Helm does not delete hooks with the release, set the helm.sh/hook-delete-policy annotation of the hook",
+		}}, r.Findings)
+	})
+
+	t.Run("Releases uninstalled cleanly should not add findings", func(t *testing.T) {
+		results := []valuesFileResult{{valuesFile: "ci/good-values.yaml"}}
+		r := NewResult(true, ChartTestingSuccess)
+		attachLeftovers(&r, results)
+
+		require.True(t, r.Ok)
+		require.Equal(t, ChartTestingSuccess, r.Reason)
+		require.Empty(t, r.Findings)
+	})
+
+	t.Run("Failed uninstall should be reported", func(t *testing.T) {
+		result := valuesFileResult{valuesFile: "values.yaml"}
+		_ = result.run("install", func() error { return nil })
+		_ = result.run("uninstall", func() error { return errors.New("deleting namespace release : forbidden") })
+
+		require.EqualError(t, getValuesFilesError([]valuesFileResult{result}), "deleting namespace release : forbidden")
+		require.Regexp(t, `^values.yaml : install \S+, uninstall \S+ \(failed\) : deleting namespace release : forbidden$`, result.String())
+	})
+}
+
+func getVersionError(ctx context.Context, settings *cli.EnvSettings, versions tool.KubeOpenShiftVersions) (string, error) {
 	return "", errors.New("error")
 }
 
-func getVersionGood(settings *cli.EnvSettings) (string, error) {
+func getVersionGood(ctx context.Context, settings *cli.EnvSettings, versions tool.KubeOpenShiftVersions) (string, error) {
 	return "4.7.9", nil
 }
 
@@ -167,7 +305,7 @@ func TestVersionSetting(t *testing.T) {
 
 		t.Run(tc.description, func(t *testing.T) {
 
-			err := setOCVersion(tc.opts.AnnotationHolder, tc.opts.HelmEnvSettings, tc.versioner)
+			err := setOCVersion(context.Background(), tc.opts.AnnotationHolder, tc.opts.HelmEnvSettings, kubeOpenShiftVersions(tc.opts), tc.versioner)
 
 			if len(tc.error) > 0 {
 				require.Error(t, err)
@@ -181,4 +319,14 @@ func TestVersionSetting(t *testing.T) {
 
 	}
 
+	t.Run("Versioner is given the context of the check", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		versioner := func(ctx context.Context, settings *cli.EnvSettings, versions tool.KubeOpenShiftVersions) (string, error) {
+			return "", ctx.Err()
+		}
+
+		err := setOCVersion(ctx, &testAnnotationHolder{}, nil, nil, versioner)
+		require.EqualError(t, err, "Missing OpenShift version. context canceled. And the 'openshift-version' flag has not set.")
+	})
 }
@@ -18,11 +18,13 @@ package checks
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+	"unicode"
 
-	"github.com/Masterminds/sprig"
-	"github.com/pkg/errors"
-	"golang.org/x/mod/semver"
+	"github.com/Masterminds/semver"
+	"github.com/xeipuuv/gojsonschema"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/lint"
 	"helm.sh/helm/v3/pkg/lint/support"
 
@@ -31,45 +33,86 @@ import (
 )
 
 const (
-	APIVersion2                  = "v2"
-	ReadmeExist                  = "Chart has a README"
-	ReadmeDoesNotExist           = "Chart does not have a README"
-	NotHelm3Reason               = "API version is not V2, used in Helm 3"
-	Helm3Reason                  = "API version is V2, used in Helm 3"
-	TestTemplatePrefix           = "templates/tests/"
-	ChartTestFilesExist          = "Chart test files exist"
-	ChartTestFilesDoesNotExist   = "Chart test files do not exist"
-	KuberVersionSpecified        = "Kubernetes version specified"
-	KuberVersionNotSpecified     = "Kubernetes version is not specified"
-	KuberVersionProcessingError  = "Error converting kubeVersion to an OCP range"
-	ValuesSchemaFileExist        = "Values schema file exist"
-	ValuesSchemaFileDoesNotExist = "Values schema file does not exist"
-	ValuesFileExist              = "Values file exist"
-	ValuesFileDoesNotExist       = "Values file does not exist"
-	ChartContainCRDs             = "Chart contains CRDs"
-	ChartDoesNotContainCRDs      = "Chart does not contain CRDs"
-	HelmLintSuccessful           = "Helm lint successful"
-	HelmLintHasFailedPrefix      = "Helm lint has failed: "
-	CSIObjectsExist              = "CSI objects exist"
-	CSIObjectsDoesNotExist       = "CSI objects do not exist"
-	NoImagesToCertify            = "No images to certify"
-	ImageCertifyFailed           = "Failed to certify images"
-	ImageCertified               = "Image is Red Hat certified"
-	ImageNotCertified            = "Image is not Red Hat certified"
-	ChartTestingSuccess          = "Chart tests have passed"
-	MetadataFailure              = "Empty metadata in chart"
-	RequiredAnnotationsSuccess   = "All required annotations present"
-	RequiredAnnotationsFailure   = "Missing required annotations"
+	APIVersion2                       = "v2"
+	ReadmeExist                       = "Chart has a README"
+	ReadmeDoesNotExist                = "Chart does not have a README"
+	NotHelm3Reason                    = "API version is not V2, used in Helm 3"
+	Helm3Reason                       = "API version is V2, used in Helm 3"
+	TestTemplatePrefix                = "templates/tests/"
+	ChartTestFilesExist               = "Chart test files exist"
+	ChartTestFilesDoesNotExist        = "Chart test files do not exist"
+	KuberVersionSpecified             = "Kubernetes version specified"
+	KuberVersionNotSpecified          = "Kubernetes version is not specified"
+	KuberVersionProcessingError       = "Error converting kubeVersion to an OCP range"
+	ValuesSchemaFileExist             = "Values schema file exist"
+	ValuesSchemaFileDoesNotExist      = "Values schema file does not exist"
+	ValuesFileExist                   = "Values file exist"
+	ValuesFileDoesNotExist            = "Values file does not exist"
+	ChartContainCRDs                  = "Chart contains CRDs"
+	ChartDoesNotContainCRDs           = "Chart does not contain CRDs"
+	HelmLintSuccessful                = "Helm lint successful"
+	HelmLintHasFailedPrefix           = "Helm lint has failed: "
+	CSIObjectsExist                   = "CSI objects exist"
+	CSIObjectsDoesNotExist            = "CSI objects do not exist"
+	CSIObjectsCheckFailed             = "Failed to verify CSI objects"
+	NoImagesToCertify                 = "No images to certify"
+	ImageCertifyFailed                = "Failed to certify images"
+	ImageCertified                    = "Image is Red Hat certified"
+	ImageNotCertified                 = "Image is not Red Hat certified"
+	ChartTestingSuccess               = "Chart tests have passed"
+	ChartTestingFailure               = "Chart tests have failed"
+	ChartTestingLeftoverObject        = "Object of the release left after uninstall"
+	MetadataFailure                   = "Empty metadata in chart"
+	RequiredAnnotationsSuccess        = "All required annotations present"
+	RequiredAnnotationsFailure        = "Missing required annotations"
+	APIsCompatible                    = "All rendered resources are served by OpenShift"
+	APINotServed                      = "API is not served by OpenShift"
+	APICompatibilityFailed            = "Failed to verify API compatibility"
+	DeprecatedAPIsDoNotExist          = "Deprecated or removed APIs do not exist"
+	DeprecatedAPIsCheckFailed         = "Failed to verify deprecated APIs"
+	APIDeprecated                     = "API is deprecated in Kubernetes"
+	APIRemoved                        = "API is removed in Kubernetes"
+	ClusterAdminPrivilegesNotRequired = "Chart can be installed without cluster-admin privileges"
+	ClusterAdminPrivilegesRequired    = "Object requires cluster-admin privileges"
+	ClusterAdminPrivilegesCheckFailed = "Failed to verify cluster-admin privileges"
+	PrerequisitesDeclared             = "All prerequisites are created by the chart or declared"
+	PrerequisiteNotDeclared           = "Prerequisite is not created by the chart nor declared"
+	PrerequisitesCheckFailed          = "Failed to verify prerequisites"
+	KeywordsAreCategories             = "Chart keywords include OpenShift categories"
+	KeywordsAreNotCategories          = "Chart keywords do not include an OpenShift category"
+	KeywordCloseToCategory            = "Keyword is close to an OpenShift category"
+	KeywordsCheckFailed               = "Failed to verify keywords"
+	ChartIsCommercial                 = "Chart is a commercial chart"
+	ChartIsNotCommercial              = "Chart is not a commercial chart"
+	ChartIsCommunity                  = "Chart is a community chart"
+	ChartIsNotCommunity               = "Chart is not a community chart"
+	CommercialChartIndicator          = "Commercial chart indicator"
+	CommunityChartIndicator           = "Community chart indicator"
+	ChartVendorTypeMismatch           = "Indicator does not match the vendor type of the profile"
+	ChartClassificationFailed         = "Failed to classify chart"
+	InfraPluginsAndDriversDoNotExist  = "Infrastructure plugins and drivers do not exist"
+	InfraPluginOrDriverExists         = "Infrastructure plugin or driver exists"
+	InfraPluginsAndDriversCheckFailed = "Failed to verify infrastructure plugins and drivers"
+	PodSecurityRestricted             = "All pods comply with the restricted pod security profile"
+	PodSecurityViolation              = "Pod does not comply with the restricted pod security profile"
+	PodSecurityCheckFailed            = "Failed to verify pod security"
+	ResourcesAndProbesSet             = "All containers set resources and probes"
+	ResourcesOrProbesMissing          = "Container does not set resources or probes"
+	PinnedImageAlwaysPulled           = "Container always pulls an image pinned by digest"
+	ResourcesAndProbesCheckFailed     = "Failed to verify resources and probes"
+	ValuesSchemaValid                 = "Values match the values schema"
+	ValuesSchemaViolation             = "Values do not match the values schema"
+	ValuesSchemaTooPermissive         = "Values schema is too permissive"
+	ValuesSchemaCheckFailed           = "Failed to verify values against the values schema"
+	HelmLintCheckFailed               = "Failed to run helm lint"
+	PyxisUnavailable                  = "Images could not be verified, Pyxis is unavailable"
+	NoClusterAvailable                = "No cluster is available to install the chart"
 )
 
 var (
 	requiredAnnotations = [...]string{"charts.openshift.io/name"}
 )
 
-func notImplemented() (Result, error) {
-	return Result{Ok: false}, errors.New("not implemented")
-}
-
 func IsHelmV3(opts *CheckOptions) (Result, error) {
 	c, _, err := LoadChartFromURI(opts.URI)
 	if err != nil {
@@ -149,16 +192,56 @@ func ContainsValuesSchema(opts *CheckOptions) (Result, error) {
 	return r, nil
 }
 
-func KeywordsAreOpenshiftCategories(opts *CheckOptions) (Result, error) {
-	return notImplemented()
-}
+func ContainsValuesSchema_V1_1(opts *CheckOptions) (Result, error) {
+	c, chartPath, err := LoadChartFromURI(opts.URI)
+	if err != nil {
+		return Result{}, err
+	}
 
-func IsCommercialChart(opts *CheckOptions) (Result, error) {
-	return notImplemented()
-}
+	if len(c.Schema) == 0 {
+		return NewResult(false, ValuesSchemaFileDoesNotExist), nil
+	}
+
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(c.Schema))
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s : %s : %v", ValuesSchemaCheckFailed, chartutil.SchemafileName, err)), nil
+	}
+
+	r := NewResult(true, "")
+	for _, laxity := range getSchemaLaxities(c.Schema) {
+		r.AddFinding(Finding{
+			Severity:    ErrorFindingSeverity,
+			Message:     fmt.Sprintf("%s : %s", ValuesSchemaTooPermissive, laxity),
+			Path:        chartutil.SchemafileName,
+			Remediation: "define the properties of the values and set additionalProperties to false",
+		})
+	}
 
-func IsCommunityChart(opts *CheckOptions) (Result, error) {
-	return notImplemented()
+	sources, err := getValuesSources(c, chartPath, opts.Values)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s : %v", ValuesSchemaCheckFailed, err)), nil
+	}
+	for _, source := range sources {
+		violations, err := getSchemaViolations(schema, source.values)
+		if err != nil {
+			return NewResult(false, fmt.Sprintf("%s : %s : %v", ValuesSchemaCheckFailed, source.name, err)), nil
+		}
+		for _, violation := range violations {
+			r.AddFinding(Finding{
+				Severity:    ErrorFindingSeverity,
+				Message:     fmt.Sprintf("%s : %s : %q : %s", ValuesSchemaViolation, source.name, violation.pointer, violation.description),
+				Path:        source.name,
+				Name:        violation.pointer,
+				Remediation: fmt.Sprintf("update %s or %s", source.name, chartutil.SchemafileName),
+			})
+		}
+	}
+
+	if r.Ok {
+		r.SetResult(true, ValuesSchemaValid)
+	}
+
+	return r, nil
 }
 
 func HasKubeVersion(opts *CheckOptions) (Result, error) {
@@ -185,7 +268,7 @@ func HasKubeVersion_V1_1(opts *CheckOptions) (Result, error) {
 	r := NewResult(false, KuberVersionNotSpecified)
 
 	if c.Metadata.KubeVersion != "" {
-		OCPRange, err := getOCPRange(c.Metadata.KubeVersion)
+		OCPRange, err := getOCPRange(kubeOpenShiftVersions(opts), c.Metadata.KubeVersion)
 		if err != nil {
 			r = NewResult(false, err.Error())
 		} else {
@@ -212,27 +295,83 @@ func NotContainCRDs(opts *CheckOptions) (Result, error) {
 	return r, nil
 }
 
+const (
+	// HelmLintFailWhenConfigName sets the lowest severity of the lint messages failing the check: INFO, WARNING or
+	// ERROR.
+	HelmLintFailWhenConfigName string = "failWhen"
+	// HelmLintStrictConfigName enables the strict mode of helm lint, failing on undefined values.
+	HelmLintStrictConfigName string = "strict"
+	// HelmLintNamespaceConfigName sets the namespace the chart templates are rendered in.
+	HelmLintNamespaceConfigName string = "namespace"
+
+	defaultHelmLintFailWhen  = "ERROR"
+	defaultHelmLintNamespace = "default"
+)
+
+// helmLintSeverities contains the names of the helm lint severities, indexed by severity.
+var helmLintSeverities = []string{"UNKNOWN", "INFO", "WARNING", "ERROR"}
+
+// parseHelmLintSeverity returns the severity named by a failWhen option.
+func parseHelmLintSeverity(name string) (int, error) {
+	for severity, severityName := range helmLintSeverities {
+		if severity >= support.InfoSev && strings.EqualFold(name, severityName) {
+			return severity, nil
+		}
+	}
+	return 0, fmt.Errorf("%s : invalid %s %s, expected INFO, WARNING or ERROR", HelmLintCheckFailed, HelmLintFailWhenConfigName, name)
+}
+
 func HelmLint(opts *CheckOptions) (Result, error) {
 	_, p, err := LoadChartFromURI(opts.URI)
 	if err != nil {
 		return NewResult(false, err.Error()), err
 	}
+
+	failWhen := opts.ViperConfig.GetString(HelmLintFailWhenConfigName)
+	if len(failWhen) == 0 {
+		failWhen = defaultHelmLintFailWhen
+	}
+	threshold, err := parseHelmLintSeverity(failWhen)
+	if err != nil {
+		return NewResult(false, err.Error()), nil
+	}
+
+	namespace := opts.ViperConfig.GetString(HelmLintNamespaceConfigName)
+	if len(namespace) == 0 {
+		namespace = defaultHelmLintNamespace
+	}
+
 	r := NewResult(true, HelmLintSuccessful)
-	linter := lint.All(p, opts.Values, "default", false)
-	if linter.HighestSeverity > support.WarningSev {
-		reason := ""
+	linter := lint.All(p, opts.Values, namespace, opts.ViperConfig.GetBool(HelmLintStrictConfigName))
+	newFinding := func(severity FindingSeverity, m support.Message) Finding {
+		return Finding{
+			Severity: severity,
+			Message:  fmt.Sprintf("%s : %s : %v", helmLintSeverities[m.Severity], m.Path, m.Err),
+			Path:     m.Path,
+		}
+	}
+	if linter.HighestSeverity >= threshold {
+		r.SetResult(false, fmt.Sprintf("%smessages with severity %s or higher", HelmLintHasFailedPrefix, helmLintSeverities[threshold]))
 		for _, m := range linter.Messages {
-			reason = reason + m.Error() + "\n"
+			severity := InfoFindingSeverity
+			if m.Severity >= threshold {
+				severity = ErrorFindingSeverity
+			} else if m.Severity == support.WarningSev {
+				severity = WarningFindingSeverity
+			}
+			r.AddFinding(newFinding(severity, m))
+		}
+	} else {
+		// the warnings below the threshold do not fail the check, they are reported without changing its reason
+		for _, m := range linter.Messages {
+			if m.Severity == support.WarningSev {
+				r.Findings = append(r.Findings, newFinding(WarningFindingSeverity, m))
+			}
 		}
-		r.SetResult(false, fmt.Sprintf("%s %s", HelmLintHasFailedPrefix, reason))
 	}
 	return r, nil
 }
 
-func NotContainsInfraPluginsAndDrivers(opts *CheckOptions) (Result, error) {
-	return notImplemented()
-}
-
 func NotContainCSIObjects(opts *CheckOptions) (Result, error) {
 	c, _, err := LoadChartFromURI(opts.URI)
 	if err != nil {
@@ -255,19 +394,47 @@ func NotContainCSIObjects(opts *CheckOptions) (Result, error) {
 	return r, nil
 }
 
-func CanBeInstalledWithoutManualPreRequisites(opts *CheckOptions) (Result, error) {
-	return notImplemented()
+// csiKinds contains the kinds of the storage.k8s.io objects managed by CSI drivers.
+var csiKinds = map[string]bool{
+	"CSIDriver":          true,
+	"CSINode":            true,
+	"CSIStorageCapacity": true,
+	"VolumeAttachment":   true,
 }
 
-func CanBeInstalledWithoutClusterAdminPrivileges(opts *CheckOptions) (Result, error) {
-	return notImplemented()
+func NotContainCSIObjects_V1_1(opts *CheckOptions) (Result, error) {
+	_, _, err := LoadChartFromURI(opts.URI)
+	if err != nil {
+		return Result{}, err
+	}
+
+	objects, err := getRenderedObjects(opts.URI, opts.Values)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s : Failed to render chart : %v", CSIObjectsCheckFailed, err)), nil
+	}
+
+	r := NewResult(true, "")
+	for _, object := range objects {
+		if apiGroup(object.GetAPIVersion()) == "storage.k8s.io" && csiKinds[object.GetKind()] {
+			r.AddFinding(newObjectFinding(ErrorFindingSeverity, fmt.Sprintf("%s : %s/%s (%s)", CSIObjectsExist, object.GetKind(), object.GetName(), object.Source), object,
+				"install CSI drivers with an operator"))
+		}
+	}
+
+	if r.Ok {
+		r.SetResult(true, CSIObjectsDoesNotExist)
+	}
+
+	return r, nil
 }
 
 func ImagesAreCertified(opts *CheckOptions) (Result, error) {
 
+	ctx := checkContext(opts)
 	r := NewResult(true, "")
 
 	images, err := getImageReferences(opts.URI, opts.Values)
+	var unverified []string
 
 	if err != nil {
 		r.SetResult(false, fmt.Sprintf("%s : Failed to get images, error running helm template : %v", ImageCertifyFailed, err))
@@ -280,28 +447,47 @@ func ImagesAreCertified(opts *CheckOptions) (Result, error) {
 			imageRef := parseImageReference(image)
 
 			if len(imageRef.Registries) == 0 {
-				imageRef.Registries, err = pyxis.GetImageRegistries(imageRef.Repository)
+				imageRef.Registries, err = pyxis.GetImageRegistries(ctx, imageRef.Repository)
 			}
 
-			if err != nil {
-				r.AddResult(false, fmt.Sprintf("%s : %s : %v", ImageNotCertified, image, err))
-			} else if len(imageRef.Registries) == 0 {
-				r.AddResult(false, fmt.Sprintf("%s : %s", ImageNotCertified, image))
-			} else {
-				certified, checkImageErr := pyxis.IsImageInRegistry(imageRef)
-				if !certified {
-					if checkImageErr != nil {
-						r.AddResult(false, fmt.Sprintf("%s : %s : %v", ImageNotCertified, image, checkImageErr))
-					} else {
-						r.AddResult(false, fmt.Sprintf("%s : %s", ImageNotCertified, image))
-					}
-				} else {
-					r.AddResult(true, fmt.Sprintf("%s : %s", ImageCertified, image))
+			if err == nil && len(imageRef.Registries) > 0 {
+				var certified bool
+				if certified, err = pyxis.IsImageInRegistry(ctx, imageRef); certified {
+					r.AddFinding(Finding{Severity: InfoFindingSeverity, Message: fmt.Sprintf("%s : %s", ImageCertified, image), Name: image})
+					continue
 				}
 			}
+
+			// the requests fail once the check times out or is interrupted, the image is not the cause
+			if ctx.Err() != nil {
+				return Result{}, fmt.Errorf("%w : %v", ctx.Err(), err)
+			}
+
+			if pyxis.IsUnavailable(err) {
+				unverified = append(unverified, fmt.Sprintf("%s : %v", image, err))
+				continue
+			}
+
+			reason := fmt.Sprintf("%s : %s", ImageNotCertified, image)
+			if err != nil {
+				reason = fmt.Sprintf("%s : %v", reason, err)
+			}
+			r.AddFinding(Finding{
+				Severity:    ErrorFindingSeverity,
+				Message:     reason,
+				Name:        image,
+				Remediation: "use an image certified in a Red Hat registry",
+			})
 		}
 	}
 
+	// images which are not certified fail the check, otherwise the check is skipped if any image could not be verified
+	if r.Ok && len(unverified) > 0 {
+		skipped := NewSkippedResult(fmt.Sprintf("%s : %s", PyxisUnavailable, strings.Join(unverified, ", ")))
+		skipped.Findings = r.Findings
+		return skipped, nil
+	}
+
 	return r, nil
 }
 
@@ -362,41 +548,127 @@ func parseImageReference(image string) pyxis.ImageReference {
 
 }
 
-func getOCPRange(kubeVersionRange string) (string, error) {
+var (
+	// kubeVersionOperatorRegex matches the operator of a term of a kubeVersion constraint and the spaces following it.
+	kubeVersionOperatorRegex = regexp.MustCompile(`(<=|=<|>=|=>|!=|~>|<|>|=|~|\^)\s+`)
+	// kubeVersionHyphenRangeRegex matches a hyphen range of a kubeVersion constraint, such as 1.20 - 1.22.
+	kubeVersionHyphenRangeRegex = regexp.MustCompile(`\S+\s+-\s+(\S+)`)
+	// kubeVersionTermRegex matches a term of a kubeVersion constraint, capturing its operator and the major, minor and
+	// patch of its version.
+	kubeVersionTermRegex = regexp.MustCompile(`^(<=|=<|>=|=>|!=|~>|<|>|=|~|\^)?v?([0-9xX*]+)(?:\.([0-9xX*]+))?(?:\.([0-9xX*]+))?`)
+)
 
-	semverCompare := sprig.GenericFuncMap()["semverCompare"].(func(string, string) (bool, error))
-	minOCPVersion := ""
-	maxOCPVersion := ""
-	for kubeVersion, OCPVersion := range tool.GetKubeOpenShiftVersionMap() {
-		match, err := semverCompare(kubeVersionRange, kubeVersion)
-		if err != nil {
-			return "", fmt.Errorf("%s : %s", KuberVersionProcessingError, err)
+// isWildcardVersionPart returns whether a part of the version of a kubeVersion term matches any number.
+func isWildcardVersionPart(part string) bool {
+	return part == "" || part == "x" || part == "X" || part == "*"
+}
+
+// isKubeVersionUpperBound returns whether a term of a kubeVersion constraint bounds the versions below the next major
+// version: <1.25, <=1.24, 1.22, 1.22.*, ~1.22 and the second version of 1.21 - 1.24 do, while <2.0, 1.*, ^1.22 and
+// the terms bounding the versions from below do not.
+func isKubeVersionUpperBound(term string) bool {
+	match := kubeVersionTermRegex.FindStringSubmatch(term)
+	if match == nil {
+		return false
+	}
+	operator, minor, patch := match[1], match[3], match[4]
+	switch operator {
+	case "<":
+		// <2.0 bounds the versions at the next major version
+		return !(isWildcardVersionPart(minor) || minor == "0") || !(isWildcardVersionPart(patch) || patch == "0")
+	case "<=", "=<", "", "=", "~", "~>":
+		return !isWildcardVersionPart(minor)
+	}
+	return false
+}
+
+// isOpenEndedKubeVersion returns whether a kubeVersion constraint matches the Kubernetes versions to come. The
+// constraint is split in alternatives on ||, and each alternative in terms on commas and spaces, a hyphen range being
+// replaced by the <= term of its second version. The constraint is open ended when any of its alternatives has no term
+// bounding the versions below the next major version, that is no <, <=, exact or ~ term, nor hyphen range: such as
+// >=1.20, ^1.20 or >=1.20, <2.0, while 1.21 - 1.24, <1.25 and ~1.21 are bounded.
+func isOpenEndedKubeVersion(kubeVersionRange string) bool {
+	for _, alternative := range strings.Split(kubeVersionRange, "||") {
+		alternative = kubeVersionHyphenRangeRegex.ReplaceAllString(alternative, "<=$1")
+		alternative = kubeVersionOperatorRegex.ReplaceAllString(alternative, "$1")
+		bounded := false
+		for _, term := range strings.FieldsFunc(alternative, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+			bounded = bounded || isKubeVersionUpperBound(term)
 		}
-		if match {
-			testOCPVersion := fmt.Sprintf("v%s", OCPVersion)
-			if minOCPVersion == "" || semver.Compare(testOCPVersion, fmt.Sprintf("v%s", minOCPVersion)) < 0 {
-				minOCPVersion = OCPVersion
-			}
-			if maxOCPVersion == "" || semver.Compare(testOCPVersion, fmt.Sprintf("v%s", maxOCPVersion)) > 0 {
-				maxOCPVersion = OCPVersion
-			}
+		if !bounded {
+			return true
 		}
 	}
-	// Check if min ocp range is open ended, for example 1.* or >-=1.20
-	// To do this see if 1.999 is valid for the min OCP version range, not perfect but works until kubernetes hits 2.0.
-	if minOCPVersion != "" {
-		match, _ := semverCompare(kubeVersionRange, "1.999")
-		if match {
-			return fmt.Sprintf(">=%s", minOCPVersion), nil
-		} else {
-			if minOCPVersion == maxOCPVersion {
-				return minOCPVersion, nil
-			} else {
-				return fmt.Sprintf("%s - %s", minOCPVersion, maxOCPVersion), nil
-			}
+	return false
+}
+
+// getKubeOpenShiftVersionsInRange returns the OpenShift releases whose Kubernetes version matches the given
+// kubeVersion constraint, sorted by Kubernetes version, and whether the constraint is open ended, such as >=1.20 or
+// ^1.20, rather than bounded, such as 1.21 - 1.24 or <1.25.
+func getKubeOpenShiftVersionsInRange(versions tool.KubeOpenShiftVersions, kubeVersionRange string) (tool.KubeOpenShiftVersions, bool, error) {
+	constraint, err := semver.NewConstraint(kubeVersionRange)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s : %s", KuberVersionProcessingError, err)
+	}
+
+	var matching tool.KubeOpenShiftVersions
+	for _, version := range versions {
+		kubeVersion, err := semver.NewVersion(version.KubeVersion)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s : %s", KuberVersionProcessingError, err)
+		}
+		if constraint.Check(kubeVersion) {
+			matching = append(matching, version)
 		}
 	}
+	return matching, isOpenEndedKubeVersion(kubeVersionRange), nil
+}
 
-	return "", fmt.Errorf("%s : Failed to determine a minimum OCP version", KuberVersionProcessingError)
+// getKubeVersionsInRange returns the Kubernetes versions of the given versions matching the kubeVersion constraint.
+func getKubeVersionsInRange(versions tool.KubeOpenShiftVersions, kubeVersionRange string) ([]string, error) {
+	matching, _, err := getKubeOpenShiftVersionsInRange(versions, kubeVersionRange)
+	if err != nil {
+		return nil, err
+	}
+	var kubeVersions []string
+	for _, version := range matching {
+		kubeVersions = append(kubeVersions, version.KubeVersion)
+	}
+	return kubeVersions, nil
+}
 
+// getOCPRange converts a kubeVersion constraint to the range of the OpenShift versions it matches, such as 4.8,
+// 4.7 - 4.9, or >=4.7 when the constraint is open ended.
+func getOCPRange(versions tool.KubeOpenShiftVersions, kubeVersionRange string) (string, error) {
+	matching, openEnded, err := getKubeOpenShiftVersionsInRange(versions, kubeVersionRange)
+	if err != nil {
+		return "", err
+	}
+	if len(matching) == 0 {
+		return "", fmt.Errorf("%s : Failed to determine a minimum OCP version", KuberVersionProcessingError)
+	}
+
+	var minOCPVersion, maxOCPVersion *semver.Version
+	minOCPRange, maxOCPRange := "", ""
+	for _, version := range matching {
+		OCPVersion, err := semver.NewVersion(version.OpenShiftVersion)
+		if err != nil {
+			return "", fmt.Errorf("%s : %s", KuberVersionProcessingError, err)
+		}
+		if minOCPVersion == nil || OCPVersion.LessThan(minOCPVersion) {
+			minOCPVersion, minOCPRange = OCPVersion, version.OpenShiftVersion
+		}
+		if maxOCPVersion == nil || OCPVersion.GreaterThan(maxOCPVersion) {
+			maxOCPVersion, maxOCPRange = OCPVersion, version.OpenShiftVersion
+		}
+	}
+
+	switch {
+	case openEnded:
+		return fmt.Sprintf(">=%s", minOCPRange), nil
+	case minOCPRange == maxOCPRange:
+		return minOCPRange, nil
+	default:
+		return fmt.Sprintf("%s - %s", minOCPRange, maxOCPRange), nil
+	}
 }
@@ -17,11 +17,15 @@
 package checks
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/redhat-certification/chart-verifier/pkg/chartverifier/pyxis"
+	"github.com/redhat-certification/chart-verifier/pkg/tool"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 	"helm.sh/helm/v3/pkg/cli"
@@ -176,6 +180,69 @@ func TestHasValuesSchema(t *testing.T) {
 	}
 }
 
+func TestHasValuesSchema_V1_1(t *testing.T) {
+	type testCase struct {
+		description string
+		uri         string
+		values      map[string]interface{}
+		reason      string
+	}
+
+	positiveTestCases := []testCase{
+		{description: "Values match the values schema", uri: "chart-0.1.0-v3.valid.tgz", reason: ValuesSchemaValid},
+		{
+			description: "User values match the values schema",
+			uri:         "chart-0.1.0-v3.valid.tgz",
+			values:      map[string]interface{}{"port": 8080},
+			reason:      ValuesSchemaValid,
+		},
+	}
+
+	for _, tc := range positiveTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			r, err := ContainsValuesSchema_V1_1(&CheckOptions{URI: tc.uri, Values: tc.values, ViperConfig: config, HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.True(t, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+
+	negativeTestCases := []testCase{
+		{description: "Values schema file does not exist", uri: "chart-0.1.0-v3.no-values-schema.tgz", reason: ValuesSchemaFileDoesNotExist},
+		{
+			description: "User values do not match the values schema",
+			uri:         "chart-0.1.0-v3.valid.tgz",
+			values:      map[string]interface{}{"port": "http"},
+			reason:      ValuesSchemaViolation + " : " + UserValuesSource + " : \"/port\" : Invalid type. Expected: integer, given: string",
+		},
+		{
+			description: "CI values do not match the values schema",
+			uri:         "chart-0.1.0-v3.values-schema.tgz",
+			reason: ValuesSchemaViolation + " : ci/bad-values.yaml : \"/image/tag\" : Invalid type. Expected: string, given: integer\n" +
+				ValuesSchemaViolation + " : ci/bad-values.yaml : \"/port\" : Must be greater than or equal to 0",
+		},
+		{
+			description: "Values schema is too permissive",
+			uri:         "chart-0.1.0-v3.permissive-schema.tgz",
+			reason: ValuesSchemaTooPermissive + " : no properties are defined at the root\n" +
+				ValuesSchemaTooPermissive + " : additionalProperties is true at the root",
+		},
+	}
+
+	for _, tc := range negativeTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			r, err := ContainsValuesSchema_V1_1(&CheckOptions{URI: tc.uri, Values: tc.values, ViperConfig: config, HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.False(t, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+}
+
 func TestHasValues(t *testing.T) {
 	type testCase struct {
 		description string
@@ -325,21 +392,93 @@ func TestNotContainCSIObjects(t *testing.T) {
 	}
 }
 
+func TestNotContainCSIObjects_V1_1(t *testing.T) {
+	type testCase struct {
+		description string
+		uri         string
+		values      map[string]interface{}
+		reason      string
+	}
+
+	positiveTestCases := []testCase{
+		{description: "Not contain CSI objects", uri: "chart-0.1.0-v3.valid.tgz", reason: CSIObjectsDoesNotExist},
+		{
+			description: "CSI objects disabled by values",
+			uri:         "chart-0.1.0-v3.csi-objects.tgz",
+			values:      map[string]interface{}{"csi": map[string]interface{}{"enabled": false}},
+			reason:      CSIObjectsDoesNotExist,
+		},
+	}
+
+	for _, tc := range positiveTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			r, err := NotContainCSIObjects_V1_1(&CheckOptions{URI: tc.uri, Values: tc.values, ViperConfig: config, HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.True(t, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+
+	negativeTestCases := []testCase{
+		{
+			description: "Contain CSIDriver",
+			uri:         "chart-0.1.0-v3.with-csi.tgz",
+			reason:      CSIObjectsExist + " : CSIDriver/mycsidriver.example.com (chart/templates/csidriver.yaml)",
+		},
+		{
+			description: "Contain templated CSI objects",
+			uri:         "chart-0.1.0-v3.csi-objects.tgz",
+			reason: CSIObjectsExist + " : CSIDriver/csi.chart.example.com (chart/templates/csi.yaml)\n" +
+				CSIObjectsExist + " : CSINode/testRelease-chart (chart/templates/csi.yaml)\n" +
+				CSIObjectsExist + " : CSIStorageCapacity/testRelease-chart (chart/templates/csi.yaml)\n" +
+				CSIObjectsExist + " : VolumeAttachment/testRelease-chart (chart/templates/csi.yaml)",
+		},
+	}
+
+	for _, tc := range negativeTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			r, err := NotContainCSIObjects_V1_1(&CheckOptions{URI: tc.uri, Values: tc.values, ViperConfig: config, HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.False(t, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+}
+
 func TestHelmLint(t *testing.T) {
 	type testCase struct {
 		description string
 		uri         string
+		config      map[string]interface{}
+		reason      string
 	}
 
 	positiveTestCases := []testCase{
 		{description: "Helm lint works for valid chart", uri: "chart-0.1.0-v3.valid.tgz"},
 		{description: "Helm lint works for chart with lint INFO message", uri: "chart-0.1.0-v2.lint-info.tgz"},
 		{description: "Helm lint works for chart with lint WARNING message", uri: "chart-0.1.0-v2.lint-warning.tgz"},
+		{
+			description: "Helm lint works for chart with lint INFO message when failing on WARNING",
+			uri:         "chart-0.1.0-v2.lint-info.tgz",
+			config:      map[string]interface{}{HelmLintFailWhenConfigName: "warning"},
+		},
+		{
+			description: "Helm lint works for valid chart in strict mode",
+			uri:         "chart-0.1.0-v3.valid.tgz",
+			config:      map[string]interface{}{HelmLintStrictConfigName: true, HelmLintNamespaceConfigName: "chart-namespace"},
+		},
 	}
 
 	for _, tc := range positiveTestCases {
 		t.Run(tc.description, func(t *testing.T) {
 			config := viper.New()
+			for key, value := range tc.config {
+				config.Set(key, value)
+			}
 			r, err := HelmLint(&CheckOptions{URI: tc.uri, ViperConfig: config, HelmEnvSettings: cli.New()})
 			require.NoError(t, err)
 			require.NotNil(t, r)
@@ -348,18 +487,60 @@ func TestHelmLint(t *testing.T) {
 		})
 	}
 
+	t.Run("Helm lint reports WARNING messages below the failWhen severity", func(t *testing.T) {
+		r, err := HelmLint(&CheckOptions{URI: "chart-0.1.0-v2.lint-warning.tgz", ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+		require.NoError(t, err)
+		require.True(t, r.Ok)
+		require.True(t, r.IsWarning())
+		require.Equal(t, HelmLintSuccessful, r.Reason)
+		require.NotEmpty(t, r.Findings)
+		for _, finding := range r.Findings {
+			require.Equal(t, WarningFindingSeverity, finding.Severity)
+			require.Contains(t, finding.Message, "WARNING : ")
+		}
+	})
+
+	t.Run("Helm lint does not report INFO messages below the failWhen severity", func(t *testing.T) {
+		r, err := HelmLint(&CheckOptions{URI: "chart-0.1.0-v2.lint-info.tgz", ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+		require.NoError(t, err)
+		require.True(t, r.Ok)
+		require.False(t, r.IsWarning())
+		require.Empty(t, r.Findings)
+	})
+
 	negativeTestCases := []testCase{
-		{description: "Helm lint fails for chart with lint error", uri: "chart-0.1.0-v2.lint-error.tgz"},
+		{description: "Helm lint fails for chart with lint error", uri: "chart-0.1.0-v2.lint-error.tgz", reason: HelmLintHasFailedPrefix},
+		{
+			description: "Helm lint fails for chart with lint INFO message when failing on INFO",
+			uri:         "chart-0.1.0-v2.lint-info.tgz",
+			config:      map[string]interface{}{HelmLintFailWhenConfigName: "INFO"},
+			reason:      HelmLintHasFailedPrefix + "messages with severity INFO or higher\nINFO : Chart.yaml : icon is recommended",
+		},
+		{
+			description: "Helm lint fails for chart with lint WARNING message when failing on WARNING",
+			uri:         "chart-0.1.0-v2.lint-warning.tgz",
+			config:      map[string]interface{}{HelmLintFailWhenConfigName: "WARNING"},
+			reason:      HelmLintHasFailedPrefix + "messages with severity WARNING or higher\nWARNING : templates/deployment.yaml : object name does not conform",
+		},
+		{
+			description: "Helm lint fails for an invalid severity",
+			uri:         "chart-0.1.0-v3.valid.tgz",
+			config:      map[string]interface{}{HelmLintFailWhenConfigName: "FATAL"},
+			reason:      HelmLintCheckFailed + " : invalid failWhen FATAL, expected INFO, WARNING or ERROR",
+		},
 	}
 
 	for _, tc := range negativeTestCases {
 		t.Run(tc.description, func(t *testing.T) {
 			config := viper.New()
+			for key, value := range tc.config {
+				config.Set(key, value)
+			}
 			r, err := HelmLint(&CheckOptions{URI: tc.uri, ViperConfig: config, HelmEnvSettings: cli.New()})
 			require.NoError(t, err)
 			require.NotNil(t, r)
 			require.False(t, r.Ok)
-			require.Contains(t, r.Reason, HelmLintHasFailedPrefix)
+			require.Contains(t, r.Reason, tc.reason)
 		})
 	}
 
@@ -408,6 +589,14 @@ func TestImageCertify(t *testing.T) {
 
 }
 
+func TestImageCertifyInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ImagesAreCertified(&CheckOptions{URI: "chart-0.1.0-v3.valid.tgz", ViperConfig: viper.New(), HelmEnvSettings: cli.New(), Context: ctx})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
 func TestImageParsing(t *testing.T) {
 
 	type testCase struct {
@@ -475,6 +664,34 @@ func TestRequiredAnnotationsPresent(t *testing.T) {
 	}
 }
 
+func TestIsOpenEndedKubeVersion(t *testing.T) {
+	openEnded := map[string]bool{
+		">=1.20":               true,
+		">= 1.14.0-0":          true,
+		"^1.22":                true,
+		">1.20":                true,
+		"*":                    true,
+		"1.*":                  true,
+		"~1":                   true,
+		">=1.22, <2.0":         true,
+		"~1.21 || >=1.23":      true,
+		"1.21 - 1.24":          false,
+		"<1.25":                false,
+		"<=1.24":               false,
+		"~1.21":                false,
+		"~1.22-0":              false,
+		"1.22.*":               false,
+		"=1.22":                false,
+		">=1.20, <1.23":        false,
+		"1.19 - 1.20 || ~1.22": false,
+	}
+	for kubeVersion, expected := range openEnded {
+		t.Run(kubeVersion, func(t *testing.T) {
+			require.Equal(t, expected, isOpenEndedKubeVersion(kubeVersion))
+		})
+	}
+}
+
 func TestSemVers(t *testing.T) {
 
 	// Vault: kubeVersion: '>= 1.14.0-0'
@@ -499,6 +716,8 @@ func TestSemVers(t *testing.T) {
 		{kubeVersion: "^1.22", OCPRange: ">=4.9"},
 		{kubeVersion: ">=1.20-0", OCPRange: ">=4.7"},
 		{kubeVersion: "1.21 - 1.22", OCPRange: "4.8 - 4.9"},
+		{kubeVersion: "1.21 - 1.24", OCPRange: "4.8 - 4.9"},
+		{kubeVersion: "<1.25", OCPRange: "4.1 - 4.9"},
 		{kubeVersion: ">1.20", OCPRange: ">=4.8"},
 		{kubeVersion: "~1.21", OCPRange: "4.8"},
 		{kubeVersion: ">= 1.14.0-0", OCPRange: ">=4.2"},
@@ -509,7 +728,7 @@ func TestSemVers(t *testing.T) {
 
 	for _, test := range testCases {
 		t.Run(fmt.Sprintf("Check kube version %s", test.kubeVersion), func(t *testing.T) {
-			OCPRange, err := getOCPRange(test.kubeVersion)
+			OCPRange, err := getOCPRange(tool.DefaultKubeOpenShiftVersions(), test.kubeVersion)
 			if err != nil {
 				require.Equal(t, test.OCPRange, fmt.Sprintf("%v", err))
 			} else {
@@ -518,4 +737,41 @@ func TestSemVers(t *testing.T) {
 		})
 	}
 
+	// a range is bounded or open ended independently of the latest known Kubernetes version
+	versionsFile := filepath.Join(t.TempDir(), "versions.yaml")
+	require.NoError(t, ioutil.WriteFile(versionsFile, []byte(`version: v1
+versions:
+  - kubeVersion: "1.21"
+    openshiftVersion: "4.8"
+    status: full-support
+  - kubeVersion: "1.22"
+    openshiftVersion: "4.9"
+    status: full-support
+  - kubeVersion: "1.23"
+    openshiftVersion: "4.10"
+    status: full-support
+`), 0644))
+	updatedVersions, err := tool.LoadKubeOpenShiftVersions(versionsFile)
+	require.NoError(t, err)
+
+	updatedTestCases := []testCase{
+		{kubeVersion: "~1.22-0", OCPRange: "4.9"},
+		{kubeVersion: "<1.23", OCPRange: "4.8 - 4.9"},
+		{kubeVersion: "1.22 - 1.23", OCPRange: "4.9 - 4.10"},
+		{kubeVersion: "1.21 - 1.24", OCPRange: "4.8 - 4.10"},
+		{kubeVersion: "^1.22", OCPRange: ">=4.9"},
+		{kubeVersion: ">=1.22, <2.0", OCPRange: ">=4.9"},
+		{kubeVersion: "<1.21", OCPRange: "Error converting kubeVersion to an OCP range : Failed to determine a minimum OCP version"},
+	}
+
+	for _, test := range updatedTestCases {
+		t.Run(fmt.Sprintf("Check kube version %s with updated versions", test.kubeVersion), func(t *testing.T) {
+			OCPRange, err := getOCPRange(updatedVersions, test.kubeVersion)
+			if err != nil {
+				require.Equal(t, test.OCPRange, fmt.Sprintf("%v", err))
+			} else {
+				require.Equal(t, test.OCPRange, OCPRange)
+			}
+		})
+	}
 }
@@ -0,0 +1,180 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const (
+	ProviderAnnotation     = "charts.openshift.io/provider"
+	ProviderTypeAnnotation = "charts.openshift.io/providerType"
+
+	partnerVendorType   = "partner"
+	redhatVendorType    = "redhat"
+	communityVendorType = "community"
+
+	defaultImageRegistry = "docker.io"
+)
+
+// redhatImageRegistries contains the registries serving Red Hat and certified partner images.
+var redhatImageRegistries = map[string]bool{
+	"registry.access.redhat.com":  true,
+	"registry.connect.redhat.com": true,
+	"registry.redhat.io":          true,
+}
+
+// chartClassification contains the indicators of a chart being a commercial or a community chart.
+type chartClassification struct {
+	commercial []string
+	community  []string
+}
+
+// isCommercial returns whether the chart has more commercial than community indicators.
+func (c chartClassification) isCommercial() bool {
+	return len(c.commercial) > len(c.community)
+}
+
+// classifyChart collects the commercial and community indicators from the chart annotations, its maintainers and the
+// registries of the images it references.
+func classifyChart(c *chart.Chart, images []string) chartClassification {
+	classification := chartClassification{}
+
+	switch providerType := strings.ToLower(c.Metadata.Annotations[ProviderTypeAnnotation]); providerType {
+	case partnerVendorType, redhatVendorType:
+		classification.commercial = append(classification.commercial, fmt.Sprintf("annotation %s is %s", ProviderTypeAnnotation, providerType))
+	case communityVendorType:
+		classification.community = append(classification.community, fmt.Sprintf("annotation %s is %s", ProviderTypeAnnotation, providerType))
+	}
+
+	if provider := c.Metadata.Annotations[ProviderAnnotation]; strings.EqualFold(provider, "Red Hat") {
+		classification.commercial = append(classification.commercial, fmt.Sprintf("annotation %s is %s", ProviderAnnotation, provider))
+	}
+
+	for _, maintainer := range c.Metadata.Maintainers {
+		if maintainer != nil && strings.HasSuffix(strings.ToLower(maintainer.Email), "@redhat.com") {
+			classification.commercial = append(classification.commercial, fmt.Sprintf("maintainer %s is from Red Hat", maintainer.Email))
+		}
+	}
+
+	redhatRegistries := make(map[string]bool)
+	otherRegistries := make(map[string]bool)
+	for _, image := range images {
+		registry := defaultImageRegistry
+		if imageRef := parseImageReference(image); len(imageRef.Registries) > 0 {
+			registry = imageRef.Registries[0]
+		}
+		if redhatImageRegistries[registry] {
+			redhatRegistries[registry] = true
+		} else {
+			otherRegistries[registry] = true
+		}
+	}
+	if len(redhatRegistries) > 0 && len(otherRegistries) == 0 {
+		classification.commercial = append(classification.commercial, fmt.Sprintf("all images are from %s", joinKeys(redhatRegistries)))
+	} else if len(otherRegistries) > 0 && len(redhatRegistries) == 0 {
+		classification.community = append(classification.community, fmt.Sprintf("all images are from %s", joinKeys(otherRegistries)))
+	}
+
+	return classification
+}
+
+// joinKeys returns the sorted keys of a set, separated by commas.
+func joinKeys(set map[string]bool) string {
+	var keys []string
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
+
+// addClassificationIndicators adds the indicators to the result, warning about those which do not match the vendor
+// type of the profile in use.
+func addClassificationIndicators(r *Result, classification chartClassification, profileVendorType string) {
+	for _, indicator := range classification.commercial {
+		r.AddFinding(Finding{Severity: InfoFindingSeverity, Message: fmt.Sprintf("%s : %s", CommercialChartIndicator, indicator)})
+	}
+	for _, indicator := range classification.community {
+		r.AddFinding(Finding{Severity: InfoFindingSeverity, Message: fmt.Sprintf("%s : %s", CommunityChartIndicator, indicator)})
+	}
+
+	var mismatches []string
+	switch profileVendorType {
+	case partnerVendorType, redhatVendorType:
+		mismatches = classification.community
+	case communityVendorType:
+		mismatches = classification.commercial
+	}
+	for _, indicator := range mismatches {
+		r.AddFinding(Finding{
+			Severity:    WarningFindingSeverity,
+			Message:     fmt.Sprintf("%s %s : %s", ChartVendorTypeMismatch, profileVendorType, indicator),
+			Remediation: "verify the chart with the profile of its vendor type",
+		})
+	}
+}
+
+// IsCommercialChart verifies the chart is classified as a commercial chart from its annotations, maintainers and
+// image registries. Indicators which do not match the vendor type of the profile in use are reported as warnings.
+func IsCommercialChart(opts *CheckOptions) (Result, error) {
+	c, _, err := LoadChartFromURI(opts.URI)
+	if err != nil {
+		return Result{}, err
+	}
+
+	images, err := getImageReferences(opts.URI, opts.Values)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s : Failed to get images, error running helm template : %v", ChartClassificationFailed, err)), nil
+	}
+	classification := classifyChart(c, images)
+
+	r := NewResult(false, ChartIsNotCommercial)
+	if classification.isCommercial() {
+		r.SetResult(true, ChartIsCommercial)
+	}
+	addClassificationIndicators(&r, classification, opts.ProfileVendorType)
+
+	return r, nil
+}
+
+// IsCommunityChart verifies the chart is classified as a community chart from its annotations, maintainers and
+// image registries. Indicators which do not match the vendor type of the profile in use are reported as warnings.
+func IsCommunityChart(opts *CheckOptions) (Result, error) {
+	c, _, err := LoadChartFromURI(opts.URI)
+	if err != nil {
+		return Result{}, err
+	}
+
+	images, err := getImageReferences(opts.URI, opts.Values)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s : Failed to get images, error running helm template : %v", ChartClassificationFailed, err)), nil
+	}
+	classification := classifyChart(c, images)
+
+	r := NewResult(false, ChartIsNotCommunity)
+	if !classification.isCommercial() {
+		r.SetResult(true, ChartIsCommunity)
+	}
+	addClassificationIndicators(&r, classification, opts.ProfileVendorType)
+
+	return r, nil
+}
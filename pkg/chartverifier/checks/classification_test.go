@@ -0,0 +1,134 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+const (
+	commercialProviderTypeIndicator = "annotation charts.openshift.io/providerType is partner"
+	commercialImagesIndicator       = "all images are from registry.connect.redhat.com, registry.redhat.io"
+	communityImagesIndicator        = "all images are from docker.io, icr.io"
+)
+
+type classificationTestCase struct {
+	description       string
+	uri               string
+	values            map[string]interface{}
+	profileVendorType string
+	reason            string
+}
+
+func TestIsCommercialChart(t *testing.T) {
+
+	positiveTestCases := []classificationTestCase{
+		{
+			description:       "partner chart using Red Hat registries",
+			uri:               "chart-0.1.0-v3.commercial.tgz",
+			profileVendorType: partnerVendorType,
+			reason: ChartIsCommercial + "\n" +
+				CommercialChartIndicator + " : " + commercialProviderTypeIndicator + "\n" +
+				CommercialChartIndicator + " : " + commercialImagesIndicator,
+		},
+	}
+
+	for _, tc := range positiveTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r, err := IsCommercialChart(&CheckOptions{URI: tc.uri, Values: tc.values, ProfileVendorType: tc.profileVendorType, ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.True(t, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+
+	negativeTestCases := []classificationTestCase{
+		{
+			description:       "community chart verified with the partner profile",
+			uri:               "chart-0.1.0-v3.valid.tgz",
+			profileVendorType: partnerVendorType,
+			reason: ChartIsNotCommercial + "\n" +
+				CommunityChartIndicator + " : " + communityImagesIndicator + "\n" +
+				ChartVendorTypeMismatch + " partner : " + communityImagesIndicator,
+		},
+	}
+
+	for _, tc := range negativeTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r, err := IsCommercialChart(&CheckOptions{URI: tc.uri, Values: tc.values, ProfileVendorType: tc.profileVendorType, ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.False(t, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+}
+
+func TestIsCommunityChart(t *testing.T) {
+
+	positiveTestCases := []classificationTestCase{
+		{
+			description:       "chart without commercial indicators",
+			uri:               "chart-0.1.0-v3.valid.tgz",
+			profileVendorType: communityVendorType,
+			reason:            ChartIsCommunity + "\n" + CommunityChartIndicator + " : " + communityImagesIndicator,
+		},
+	}
+
+	for _, tc := range positiveTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r, err := IsCommunityChart(&CheckOptions{URI: tc.uri, Values: tc.values, ProfileVendorType: tc.profileVendorType, ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.True(t, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+
+	negativeTestCases := []classificationTestCase{
+		{
+			description:       "partner chart verified with the community profile",
+			uri:               "chart-0.1.0-v3.commercial.tgz",
+			profileVendorType: communityVendorType,
+			reason: ChartIsNotCommunity + "\n" +
+				CommercialChartIndicator + " : " + commercialProviderTypeIndicator + "\n" +
+				CommercialChartIndicator + " : " + commercialImagesIndicator + "\n" +
+				ChartVendorTypeMismatch + " community : " + commercialProviderTypeIndicator + "\n" +
+				ChartVendorTypeMismatch + " community : " + commercialImagesIndicator,
+		},
+	}
+
+	for _, tc := range negativeTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r, err := IsCommunityChart(&CheckOptions{URI: tc.uri, Values: tc.values, ProfileVendorType: tc.profileVendorType, ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.False(t, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+
+			// the severity of the finding already says it is a warning
+			mismatch := r.Findings[len(r.Findings)-1]
+			require.Equal(t, WarningFindingSeverity, mismatch.Severity)
+			require.Equal(t, "Indicator does not match the vendor type of the profile community : "+commercialImagesIndicator, mismatch.Message)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// clusterScopedKinds contains the well known cluster-scoped kinds, keyed by API group and kind.
+var clusterScopedKinds = map[string]bool{
+	"/ComponentStatus":  true,
+	"/Namespace":        true,
+	"/Node":             true,
+	"/PersistentVolume": true,
+
+	"admissionregistration.k8s.io/MutatingWebhookConfiguration":   true,
+	"admissionregistration.k8s.io/ValidatingWebhookConfiguration": true,
+	"apiextensions.k8s.io/CustomResourceDefinition":               true,
+	"apiregistration.k8s.io/APIService":                           true,
+	"authentication.k8s.io/TokenReview":                           true,
+	"authorization.k8s.io/SelfSubjectAccessReview":                true,
+	"authorization.k8s.io/SelfSubjectRulesReview":                 true,
+	"authorization.k8s.io/SubjectAccessReview":                    true,
+	"certificates.k8s.io/CertificateSigningRequest":               true,
+	"extensions/PodSecurityPolicy":                                true,
+	"flowcontrol.apiserver.k8s.io/FlowSchema":                     true,
+	"flowcontrol.apiserver.k8s.io/PriorityLevelConfiguration":     true,
+	"networking.k8s.io/IngressClass":                              true,
+	"node.k8s.io/RuntimeClass":                                    true,
+	"policy/PodSecurityPolicy":                                    true,
+	"rbac.authorization.k8s.io/ClusterRole":                       true,
+	"rbac.authorization.k8s.io/ClusterRoleBinding":                true,
+	"scheduling.k8s.io/PriorityClass":                             true,
+	"storage.k8s.io/CSIDriver":                                    true,
+	"storage.k8s.io/CSINode":                                      true,
+	"storage.k8s.io/StorageClass":                                 true,
+	"storage.k8s.io/VolumeAttachment":                             true,
+
+	"config.openshift.io/APIServer":                       true,
+	"config.openshift.io/ClusterOperator":                 true,
+	"config.openshift.io/ClusterVersion":                  true,
+	"config.openshift.io/Image":                           true,
+	"config.openshift.io/Ingress":                         true,
+	"config.openshift.io/Network":                         true,
+	"config.openshift.io/OAuth":                           true,
+	"config.openshift.io/Proxy":                           true,
+	"console.openshift.io/ConsoleCLIDownload":             true,
+	"console.openshift.io/ConsoleExternalLogLink":         true,
+	"console.openshift.io/ConsoleLink":                    true,
+	"console.openshift.io/ConsoleNotification":            true,
+	"console.openshift.io/ConsolePlugin":                  true,
+	"console.openshift.io/ConsoleQuickStart":              true,
+	"console.openshift.io/ConsoleYAMLSample":              true,
+	"machineconfiguration.openshift.io/KubeletConfig":     true,
+	"machineconfiguration.openshift.io/MachineConfig":     true,
+	"machineconfiguration.openshift.io/MachineConfigPool": true,
+	"operator.openshift.io/ImageContentSourcePolicy":      true,
+	"project.openshift.io/Project":                        true,
+	"quota.openshift.io/ClusterResourceQuota":             true,
+	"security.openshift.io/SecurityContextConstraints":    true,
+	"user.openshift.io/Group":                             true,
+	"user.openshift.io/Identity":                          true,
+	"user.openshift.io/User":                              true,
+	"imageregistry.operator.openshift.io/Config":          true,
+	"samples.operator.openshift.io/Config":                true,
+}
+
+// apiGroup returns the API group of the given group version, the empty string being the core group.
+func apiGroup(groupVersion string) string {
+	if i := strings.Index(groupVersion, "/"); i >= 0 {
+		return groupVersion[:i]
+	}
+	return ""
+}
+
+// getClusterScopedCRDKinds returns the kinds defined by the chart's own CRDs whose scope is Cluster, keyed by API
+// group and kind.
+func getClusterScopedCRDKinds(objects []renderedObject) map[string]bool {
+	kinds := make(map[string]bool)
+	for _, object := range objects {
+		if object.GetKind() != "CustomResourceDefinition" {
+			continue
+		}
+		scope, _, _ := unstructured.NestedString(object.Object, "spec", "scope")
+		if scope != "Cluster" {
+			continue
+		}
+		group, _, _ := unstructured.NestedString(object.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(object.Object, "spec", "names", "kind")
+		kinds[fmt.Sprintf("%s/%s", group, kind)] = true
+	}
+	return kinds
+}
+
+// CanBeInstalledWithoutClusterAdminPrivileges verifies the chart only renders namespaced resources, which can be
+// created by a user holding the admin role in the release namespace. Every cluster-scoped resource is reported.
+func CanBeInstalledWithoutClusterAdminPrivileges(opts *CheckOptions) (Result, error) {
+	_, _, err := LoadChartFromURI(opts.URI)
+	if err != nil {
+		return Result{}, err
+	}
+
+	objects, err := getRenderedObjects(opts.URI, opts.Values)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s : Failed to render chart : %v", ClusterAdminPrivilegesCheckFailed, err)), nil
+	}
+
+	crdKinds := getClusterScopedCRDKinds(objects)
+
+	r := NewResult(true, "")
+	for _, object := range objects {
+		groupKind := fmt.Sprintf("%s/%s", apiGroup(object.GetAPIVersion()), object.GetKind())
+		if !clusterScopedKinds[groupKind] && !crdKinds[groupKind] {
+			continue
+		}
+		r.AddFinding(newObjectFinding(ErrorFindingSeverity, fmt.Sprintf("%s : %s/%s/%s (%s)", ClusterAdminPrivilegesRequired, object.GetAPIVersion(), object.GetKind(), object.GetName(), object.Source), object,
+			"use a namespaced equivalent, or install the object with an operator"))
+	}
+
+	if r.Ok {
+		r.SetResult(true, ClusterAdminPrivilegesNotRequired)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+func TestCanBeInstalledWithoutClusterAdminPrivileges(t *testing.T) {
+	type testCase struct {
+		description string
+		uri         string
+		reasons     []string
+	}
+
+	positiveTestCases := []testCase{
+		{description: "chart with namespaced objects only", uri: "chart-0.1.0-v3.valid.tgz"},
+	}
+
+	for _, tc := range positiveTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r, err := CanBeInstalledWithoutClusterAdminPrivileges(&CheckOptions{URI: tc.uri, ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.True(t, r.Ok)
+			require.Equal(t, ClusterAdminPrivilegesNotRequired, r.Reason)
+		})
+	}
+
+	negativeTestCases := []testCase{
+		{
+			description: "chart with cluster-scoped objects",
+			uri:         "chart-0.1.0-v3.cluster-scoped.tgz",
+			reasons: []string{
+				ClusterAdminPrivilegesRequired + " : scheduling.k8s.io/v1/PriorityClass/testRelease-chart (chart/templates/priorityclass.yaml)",
+				ClusterAdminPrivilegesRequired + " : rbac.authorization.k8s.io/v1/ClusterRole/testRelease-chart (chart/templates/rbac.yaml)",
+				ClusterAdminPrivilegesRequired + " : rbac.authorization.k8s.io/v1/ClusterRoleBinding/testRelease-chart (chart/templates/rbac.yaml)",
+			},
+		},
+	}
+
+	for _, tc := range negativeTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r, err := CanBeInstalledWithoutClusterAdminPrivileges(&CheckOptions{URI: tc.uri, ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.False(t, r.Ok)
+			require.Len(t, strings.Split(r.Reason, "\n"), len(tc.reasons))
+			for _, reason := range tc.reasons {
+				require.Contains(t, r.Reason, reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"fmt"
+
+	"golang.org/x/mod/semver"
+)
+
+// deprecatedAPI describes a Kubernetes API deprecated in favour of a replacement.
+type deprecatedAPI struct {
+	GroupVersion string
+	Kind         string
+	DeprecatedIn string
+	RemovedIn    string
+	Replacement  string
+}
+
+// Based on https://kubernetes.io/docs/reference/using-api/deprecation-guide/
+var deprecatedAPIs = []deprecatedAPI{
+	{"extensions/v1beta1", "DaemonSet", "1.8", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "Deployment", "1.8", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "ReplicaSet", "1.8", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "NetworkPolicy", "1.9", "1.16", "networking.k8s.io/v1"},
+	{"extensions/v1beta1", "PodSecurityPolicy", "1.10", "1.16", "policy/v1beta1"},
+	{"extensions/v1beta1", "Ingress", "1.14", "1.22", "networking.k8s.io/v1"},
+	{"apps/v1beta1", "ControllerRevision", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta1", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta1", "StatefulSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "ControllerRevision", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "DaemonSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "ReplicaSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "StatefulSet", "1.9", "1.16", "apps/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "MutatingWebhookConfiguration", "1.16", "1.22", "admissionregistration.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "ValidatingWebhookConfiguration", "1.16", "1.22", "admissionregistration.k8s.io/v1"},
+	{"apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "1.16", "1.22", "apiextensions.k8s.io/v1"},
+	{"apiregistration.k8s.io/v1beta1", "APIService", "1.19", "1.22", "apiregistration.k8s.io/v1"},
+	{"authentication.k8s.io/v1beta1", "TokenReview", "1.19", "1.22", "authentication.k8s.io/v1"},
+	{"authorization.k8s.io/v1beta1", "LocalSubjectAccessReview", "1.19", "1.22", "authorization.k8s.io/v1"},
+	{"authorization.k8s.io/v1beta1", "SelfSubjectAccessReview", "1.19", "1.22", "authorization.k8s.io/v1"},
+	{"authorization.k8s.io/v1beta1", "SubjectAccessReview", "1.19", "1.22", "authorization.k8s.io/v1"},
+	{"certificates.k8s.io/v1beta1", "CertificateSigningRequest", "1.19", "1.22", "certificates.k8s.io/v1"},
+	{"coordination.k8s.io/v1beta1", "Lease", "1.19", "1.22", "coordination.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "Ingress", "1.19", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "IngressClass", "1.19", "1.22", "networking.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRole", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRoleBinding", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "Role", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "RoleBinding", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"scheduling.k8s.io/v1beta1", "PriorityClass", "1.14", "1.22", "scheduling.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSIDriver", "1.19", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSINode", "1.17", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "StorageClass", "1.19", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "VolumeAttachment", "1.19", "1.22", "storage.k8s.io/v1"},
+	{"batch/v1beta1", "CronJob", "1.21", "1.25", "batch/v1"},
+	{"discovery.k8s.io/v1beta1", "EndpointSlice", "1.21", "1.25", "discovery.k8s.io/v1"},
+	{"events.k8s.io/v1beta1", "Event", "1.19", "1.25", "events.k8s.io/v1"},
+	{"autoscaling/v2beta1", "HorizontalPodAutoscaler", "1.22", "1.25", "autoscaling/v2"},
+	{"autoscaling/v2beta2", "HorizontalPodAutoscaler", "1.23", "1.26", "autoscaling/v2"},
+	{"node.k8s.io/v1beta1", "RuntimeClass", "1.22", "1.25", "node.k8s.io/v1"},
+	{"policy/v1beta1", "PodDisruptionBudget", "1.21", "1.25", "policy/v1"},
+	{"policy/v1beta1", "PodSecurityPolicy", "1.21", "1.25", "Pod Security Admission"},
+	{"storage.k8s.io/v1beta1", "CSIStorageCapacity", "1.24", "1.27", "storage.k8s.io/v1"},
+}
+
+// getDeprecatedAPI returns the deprecation information for the given group version and kind, if any.
+func getDeprecatedAPI(groupVersion, kind string) (deprecatedAPI, bool) {
+	for _, api := range deprecatedAPIs {
+		if api.GroupVersion == groupVersion && api.Kind == kind {
+			return api, true
+		}
+	}
+	return deprecatedAPI{}, false
+}
+
+// NotContainsDeprecatedAPIs verifies the chart does not render resources using APIs deprecated or removed in the
+// Kubernetes versions matching the chart's kubeVersion; a chart without kubeVersion is verified against all known
+// versions. Removed APIs fail the check, deprecated APIs are reported without failing it.
+func NotContainsDeprecatedAPIs(opts *CheckOptions) (Result, error) {
+	c, _, err := LoadChartFromURI(opts.URI)
+	if err != nil {
+		return Result{}, err
+	}
+
+	kubeVersionRange := c.Metadata.KubeVersion
+	if len(kubeVersionRange) == 0 {
+		kubeVersionRange = "*"
+	}
+	kubeVersions, err := getKubeVersionsInRange(kubeOpenShiftVersions(opts), kubeVersionRange)
+	if err != nil {
+		return NewResult(false, err.Error()), nil
+	}
+	if len(kubeVersions) == 0 {
+		return NewResult(false, fmt.Sprintf("%s : no known Kubernetes version matches %s", DeprecatedAPIsCheckFailed, kubeVersionRange)), nil
+	}
+	maxKubeVersion := "v" + kubeVersions[len(kubeVersions)-1]
+
+	objects, err := getRenderedObjects(opts.URI, opts.Values)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s : Failed to render chart : %v", DeprecatedAPIsCheckFailed, err)), nil
+	}
+
+	r := NewResult(true, "")
+	reported := make(map[string]bool)
+	for _, object := range objects {
+		api, ok := getDeprecatedAPI(object.GetAPIVersion(), object.GetKind())
+		if !ok || semver.Compare(maxKubeVersion, "v"+api.DeprecatedIn) < 0 {
+			continue
+		}
+		gvk := fmt.Sprintf("%s/%s", api.GroupVersion, api.Kind)
+		if reported[gvk] {
+			continue
+		}
+		reported[gvk] = true
+		remediation := fmt.Sprintf("use %s", api.Replacement)
+		if semver.Compare(maxKubeVersion, "v"+api.RemovedIn) >= 0 {
+			r.AddFinding(newObjectFinding(ErrorFindingSeverity, fmt.Sprintf("%s %s : %s : %s", APIRemoved, api.RemovedIn, gvk, remediation), object, remediation))
+		} else {
+			r.AddFinding(newObjectFinding(WarningFindingSeverity, fmt.Sprintf("%s %s : %s : %s", APIDeprecated, api.DeprecatedIn, gvk, remediation), object, remediation))
+		}
+	}
+
+	if len(r.Reason) == 0 {
+		r.SetResult(true, DeprecatedAPIsDoNotExist)
+	}
+
+	return r, nil
+}
@@ -18,6 +18,8 @@ package checks
 
 import (
 	"bufio"
+	"encoding/json"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -26,6 +28,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 
 	"gopkg.in/yaml.v3"
 
@@ -39,6 +42,9 @@ import (
 	kubefake "helm.sh/helm/v3/pkg/kube/fake"
 	"helm.sh/helm/v3/pkg/storage"
 	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sjson "k8s.io/apimachinery/pkg/util/json"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
 
 	"github.com/redhat-certification/chart-verifier/pkg/helm/actions"
 )
@@ -97,6 +103,8 @@ type ChartCacheItem struct {
 }
 
 type chartCache struct {
+	// mutex guards chartMap and the cache directory, charts are loaded by checks running concurrently.
+	mutex    sync.Mutex
 	chartMap map[string]ChartCacheItem
 }
 
@@ -111,6 +119,8 @@ func (c *chartCache) MakeKey(uri string) string {
 }
 
 func (c *chartCache) Get(uri string) (ChartCacheItem, bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 	if item, ok := c.chartMap[c.MakeKey(uri)]; !ok {
 		return ChartCacheItem{}, false, nil
 	} else {
@@ -119,6 +129,12 @@ func (c *chartCache) Get(uri string) (ChartCacheItem, bool, error) {
 }
 
 func (c *chartCache) Add(uri string, chrt *chart.Chart) (ChartCacheItem, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	// another check may have loaded the same chart meanwhile, its directory must not be rewritten while in use
+	if item, ok := c.chartMap[c.MakeKey(uri)]; ok {
+		return item, nil
+	}
 	userCacheDir, err := os.UserCacheDir()
 	if err != nil {
 		return ChartCacheItem{}, err
@@ -188,7 +204,8 @@ func IsChartNotFound(err error) bool {
 	return ok
 }
 
-func getImageReferences(chartUri string, vals map[string]interface{}) ([]string, error) {
+// renderManifests renders the given chart without a cluster, returning its manifests including CRDs and hooks.
+func renderManifests(chartUri string, vals map[string]interface{}) (string, error) {
 
 	actionConfig := &action.Configuration{
 		Releases:     nil,
@@ -200,9 +217,14 @@ func getImageReferences(chartUri string, vals map[string]interface{}) ([]string,
 	mem.SetNamespace("TestNamespace")
 	actionConfig.Releases = storage.Init(mem)
 
+	return actions.RenderManifests("testRelease", chartUri, vals, actionConfig)
+}
+
+func getImageReferences(chartUri string, vals map[string]interface{}) ([]string, error) {
+
 	imagesMap := make(map[string]bool)
 
-	txt, err := actions.RenderManifests("testRelease", chartUri, vals, actionConfig)
+	txt, err := renderManifests(chartUri, vals)
 
 	type ImageRef struct {
 		Ref string `yaml:"image"`
@@ -230,3 +252,71 @@ func getImageReferences(chartUri string, vals map[string]interface{}) ([]string,
 
 	return images, err
 }
+
+// renderedObject is a resource rendered from one of the chart's templates.
+type renderedObject struct {
+	*unstructured.Unstructured
+	// Source is the chart file the object has been rendered from.
+	Source string
+}
+
+// newObjectFinding returns a finding about a rendered object.
+func newObjectFinding(severity FindingSeverity, message string, object renderedObject, remediation string) Finding {
+	return Finding{
+		Severity:    severity,
+		Message:     message,
+		Path:        object.Source,
+		Kind:        object.GetKind(),
+		Name:        object.GetName(),
+		Remediation: remediation,
+	}
+}
+
+var (
+	manifestSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+	manifestSource    = regexp.MustCompile(`(?m)^# Source: (.+)$`)
+)
+
+// getRenderedObjects renders the given chart and decodes every resulting document, in the order they were rendered.
+// Documents without a kind, such as those emptied by template conditions, are skipped.
+func getRenderedObjects(chartUri string, vals map[string]interface{}) ([]renderedObject, error) {
+
+	txt, err := renderManifests(chartUri, vals)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []renderedObject
+	for _, doc := range manifestSeparator.Split(txt, -1) {
+		if len(strings.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		source := ""
+		if match := manifestSource.FindStringSubmatch(doc); match != nil {
+			source = strings.TrimSpace(match[1])
+		}
+
+		// decode to JSON first so integers are kept as int64, as expected by unstructured objects
+		raw := json.RawMessage{}
+		decoder := k8syaml.NewYAMLOrJSONDecoder(strings.NewReader(doc), 4096)
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				continue
+			}
+			return nil, errors.Wrapf(err, "decoding %s", source)
+		}
+		obj := map[string]interface{}{}
+		if err := k8sjson.Unmarshal(raw, &obj); err != nil {
+			return nil, errors.Wrapf(err, "decoding %s", source)
+		}
+
+		u := &unstructured.Unstructured{Object: obj}
+		if len(u.GetKind()) == 0 {
+			continue
+		}
+		objects = append(objects, renderedObject{Unstructured: u, Source: source})
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// infraHostPath describes host paths mounted by a kind of infrastructure plugin or driver.
+type infraHostPath struct {
+	Plugin   string
+	Prefixes []string
+}
+
+var infraHostPaths = []infraHostPath{
+	{"CNI plugin", []string{"/etc/cni", "/opt/cni", "/var/lib/cni"}},
+	{"Device plugin", []string{"/var/lib/kubelet/device-plugins"}},
+	{"CSI node plugin", []string{"/var/lib/kubelet/plugins_registry", "/var/lib/kubelet/plugins"}},
+}
+
+// webhookKinds contains the kinds registering admission webhooks.
+var webhookKinds = map[string]bool{
+	"MutatingWebhookConfiguration":   true,
+	"ValidatingWebhookConfiguration": true,
+}
+
+// hasPathPrefix returns whether the path is the prefix or one of its sub directories.
+func hasPathPrefix(p, prefix string) bool {
+	p = path.Clean(p)
+	return p == prefix || strings.HasPrefix(p, prefix+"/")
+}
+
+// getHostPrivileges returns the host level privileges granted to a pod spec: host network and privileged containers.
+func getHostPrivileges(podSpec map[string]interface{}) []string {
+	var privileges []string
+	if hostNetwork, _, _ := unstructured.NestedBool(podSpec, "hostNetwork"); hostNetwork {
+		privileges = append(privileges, "hostNetwork")
+	}
+	containers := append(nestedMaps(podSpec, "initContainers"), nestedMaps(podSpec, "containers")...)
+	for _, container := range containers {
+		if privileged, _, _ := unstructured.NestedBool(container, "securityContext", "privileged"); privileged {
+			privileges = append(privileges, fmt.Sprintf("privileged container %s", nestedName(container, "name")))
+		}
+	}
+	return privileges
+}
+
+// getInfraPluginsAndDrivers returns the reasons a rendered object is considered an infrastructure plugin or driver.
+func getInfraPluginsAndDrivers(object renderedObject) []string {
+	var reasons []string
+	objectName := fmt.Sprintf("%s/%s (%s)", object.GetKind(), object.GetName(), object.Source)
+
+	if webhookKinds[object.GetKind()] {
+		for _, webhook := range nestedMaps(object.Object, "webhooks") {
+			reasons = append(reasons, fmt.Sprintf("Admission webhook : %s : registers webhook %s", objectName, nestedName(webhook, "name")))
+		}
+		if len(reasons) == 0 {
+			reasons = append(reasons, fmt.Sprintf("Admission webhook : %s", objectName))
+		}
+		return reasons
+	}
+
+	fields, ok := podSpecPaths[object.GetKind()]
+	if !ok {
+		return nil
+	}
+	podSpec, found, _ := unstructured.NestedMap(object.Object, fields...)
+	if !found {
+		return nil
+	}
+
+	privileges := ""
+	if hostPrivileges := getHostPrivileges(podSpec); len(hostPrivileges) > 0 {
+		privileges = fmt.Sprintf(" with %s", strings.Join(hostPrivileges, ", "))
+	}
+
+	for _, volume := range nestedMaps(podSpec, "volumes") {
+		hostPath := nestedName(volume, "hostPath", "path")
+		if len(hostPath) == 0 {
+			continue
+		}
+		for _, infra := range infraHostPaths {
+			for _, prefix := range infra.Prefixes {
+				if hasPathPrefix(hostPath, prefix) {
+					reasons = append(reasons, fmt.Sprintf("%s : %s : mounts host path %s%s", infra.Plugin, objectName, hostPath, privileges))
+					break
+				}
+			}
+		}
+	}
+
+	return reasons
+}
+
+// NotContainsInfraPluginsAndDrivers verifies the chart does not render infrastructure level components: CNI plugins,
+// device plugins and CSI node plugins, detected from the host paths their pods mount, and admission webhooks.
+func NotContainsInfraPluginsAndDrivers(opts *CheckOptions) (Result, error) {
+	_, _, err := LoadChartFromURI(opts.URI)
+	if err != nil {
+		return Result{}, err
+	}
+
+	objects, err := getRenderedObjects(opts.URI, opts.Values)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s : Failed to render chart : %v", InfraPluginsAndDriversCheckFailed, err)), nil
+	}
+
+	r := NewResult(true, "")
+	for _, object := range objects {
+		for _, reason := range getInfraPluginsAndDrivers(object) {
+			r.AddFinding(newObjectFinding(ErrorFindingSeverity, fmt.Sprintf("%s : %s", InfraPluginOrDriverExists, reason), object,
+				"install infrastructure plugins and drivers with an operator"))
+		}
+	}
+
+	if r.Ok {
+		r.SetResult(true, InfraPluginsAndDriversDoNotExist)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+func TestNotContainsInfraPluginsAndDrivers(t *testing.T) {
+	type testCase struct {
+		description string
+		uri         string
+		reason      string
+	}
+
+	positiveTestCases := []testCase{
+		{description: "chart without infrastructure plugins", uri: "chart-0.1.0-v3.valid.tgz", reason: InfraPluginsAndDriversDoNotExist},
+	}
+
+	for _, tc := range positiveTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r, err := NotContainsInfraPluginsAndDrivers(&CheckOptions{URI: tc.uri, ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.True(t, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+
+	negativeTestCases := []testCase{
+		{
+			description: "chart with infrastructure plugins",
+			uri:         "chart-0.1.0-v3.infra-plugins.tgz",
+			reason: InfraPluginOrDriverExists + " : CNI plugin : DaemonSet/testRelease-chart-cni (chart/templates/cni.yaml) : mounts host path /etc/cni/net.d with hostNetwork, privileged container install-cni\n" +
+				InfraPluginOrDriverExists + " : Device plugin : DaemonSet/testRelease-chart-device-plugin (chart/templates/cni.yaml) : mounts host path /var/lib/kubelet/device-plugins\n" +
+				InfraPluginOrDriverExists + " : Admission webhook : ValidatingWebhookConfiguration/testRelease-chart (chart/templates/cni.yaml) : registers webhook validate.chart.example.com",
+		},
+	}
+
+	for _, tc := range negativeTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r, err := NotContainsInfraPluginsAndDrivers(&CheckOptions{URI: tc.uri, ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.False(t, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+}
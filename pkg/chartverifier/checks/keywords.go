@@ -0,0 +1,137 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// maxCategoryDistance is the maximum edit distance between a keyword and a category for the category to be suggested.
+const maxCategoryDistance = 2
+
+// normalizeKeyword lower-cases a keyword and removes any character other than letters and digits.
+func normalizeKeyword(keyword string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, keyword)
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// suggestCategory returns the category closest to a keyword which is not a category, if any is close enough.
+func suggestCategory(keyword string, categories []string) (string, bool) {
+	normalized := normalizeKeyword(keyword)
+	if len(normalized) == 0 {
+		return "", false
+	}
+
+	suggestion := ""
+	bestDistance := maxCategoryDistance + 1
+	for _, category := range categories {
+		normalizedCategory := normalizeKeyword(category)
+		distance := editDistance(normalized, normalizedCategory)
+		if distance > maxCategoryDistance && len(normalized) >= 4 &&
+			(strings.HasPrefix(normalizedCategory, normalized) || strings.HasPrefix(normalized, normalizedCategory)) {
+			distance = maxCategoryDistance
+		}
+		if distance < bestDistance {
+			suggestion = category
+			bestDistance = distance
+		}
+	}
+	return suggestion, len(suggestion) > 0
+}
+
+// KeywordsAreOpenshiftCategories verifies at least one of the chart keywords is an OpenShift developer catalog
+// category of the catalogue pinned by the profile. Keywords close to a category are reported with the category.
+func KeywordsAreOpenshiftCategories(opts *CheckOptions) (Result, error) {
+	c, _, err := LoadChartFromURI(opts.URI)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if len(opts.Categories) == 0 {
+		return NewResult(false, fmt.Sprintf("%s : no OpenShift category catalogue available", KeywordsCheckFailed)), nil
+	}
+
+	categories := make(map[string]bool)
+	for _, category := range opts.Categories {
+		categories[strings.ToLower(category)] = true
+	}
+
+	var found []string
+	for _, keyword := range c.Metadata.Keywords {
+		if categories[strings.ToLower(strings.TrimSpace(keyword))] {
+			found = append(found, keyword)
+		}
+	}
+	if len(found) > 0 {
+		return NewResult(true, fmt.Sprintf("%s : %s", KeywordsAreCategories, strings.Join(found, ", "))), nil
+	}
+
+	r := NewResult(false, KeywordsAreNotCategories)
+	for _, keyword := range c.Metadata.Keywords {
+		if suggestion, ok := suggestCategory(keyword, opts.Categories); ok {
+			r.AddFinding(Finding{
+				Severity:    ErrorFindingSeverity,
+				Message:     fmt.Sprintf("%s : %s : use %s", KeywordCloseToCategory, keyword, suggestion),
+				Path:        chartutil.ChartfileName,
+				Name:        keyword,
+				Remediation: fmt.Sprintf("use %s", suggestion),
+			})
+		}
+	}
+
+	return r, nil
+}
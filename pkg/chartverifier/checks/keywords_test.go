@@ -0,0 +1,114 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+func TestKeywordsAreOpenshiftCategories(t *testing.T) {
+	type testCase struct {
+		description string
+		uri         string
+		categories  []string
+		reason      string
+	}
+
+	categories := []string{"database", "developer-tools", "monitoring", "storage"}
+
+	positiveTestCases := []testCase{
+		{
+			description: "chart with a category keyword",
+			uri:         "chart-0.1.0-v3.categories.tgz",
+			categories:  categories,
+			reason:      KeywordsAreCategories + " : database",
+		},
+	}
+
+	for _, tc := range positiveTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r, err := KeywordsAreOpenshiftCategories(&CheckOptions{URI: tc.uri, Categories: tc.categories, ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.True(t, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+
+	negativeTestCases := []testCase{
+		{
+			description: "chart without keywords",
+			uri:         "chart-0.1.0-v3.valid.tgz",
+			categories:  categories,
+			reason:      KeywordsAreNotCategories,
+		},
+		{
+			description: "chart with keywords close to categories",
+			uri:         "chart-0.1.0-v3.no-categories.tgz",
+			categories:  categories,
+			reason: KeywordsAreNotCategories + "\n" +
+				KeywordCloseToCategory + " : Databases : use database\n" +
+				KeywordCloseToCategory + " : monitor : use monitoring",
+		},
+		{
+			description: "category keyword not in the pinned catalogue",
+			uri:         "chart-0.1.0-v3.categories.tgz",
+			categories:  []string{"storage"},
+			reason:      KeywordsAreNotCategories,
+		},
+		{
+			description: "no category catalogue",
+			uri:         "chart-0.1.0-v3.categories.tgz",
+			reason:      KeywordsCheckFailed + " : no OpenShift category catalogue available",
+		},
+	}
+
+	for _, tc := range negativeTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r, err := KeywordsAreOpenshiftCategories(&CheckOptions{URI: tc.uri, Categories: tc.categories, ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.False(t, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+}
+
+func TestSuggestCategory(t *testing.T) {
+	categories := []string{"ai-machine-learning", "cicd", "database", "streaming-messaging"}
+
+	suggestions := map[string]string{
+		"databse":             "database",
+		"CI/CD":               "cicd",
+		"AI Machine Learning": "ai-machine-learning",
+		"streaming":           "streaming-messaging",
+	}
+	for keyword, expected := range suggestions {
+		suggestion, ok := suggestCategory(keyword, categories)
+		require.True(t, ok, "no suggestion for %s", keyword)
+		require.Equal(t, expected, suggestion)
+	}
+
+	for _, keyword := range []string{"postgresql", "nginx", "db", ""} {
+		_, ok := suggestCategory(keyword, categories)
+		require.False(t, ok, "unexpected suggestion for %s", keyword)
+	}
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// PodSecurityUIDRangeConfigName sets the range of user ids pods are allowed to run as, for example
+	// "1000650000-1000659999".
+	PodSecurityUIDRangeConfigName string = "uidrange"
+
+	// defaultUIDRange contains the user ids OpenShift allocates to namespaces.
+	defaultUIDRange = "1000000000-2147483647"
+
+	podSecurityRemediation = "set the pod and container security contexts to comply with the restricted profile"
+)
+
+// uidRange is an inclusive range of user ids.
+type uidRange struct {
+	min int64
+	max int64
+}
+
+func parseUIDRange(value string) (uidRange, error) {
+	parts := strings.Split(value, "-")
+	if len(parts) != 2 {
+		return uidRange{}, fmt.Errorf("%s : invalid uid range %s", PodSecurityCheckFailed, value)
+	}
+	min, minErr := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 64)
+	max, maxErr := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+	if minErr != nil || maxErr != nil || min > max {
+		return uidRange{}, fmt.Errorf("%s : invalid uid range %s", PodSecurityCheckFailed, value)
+	}
+	return uidRange{min: min, max: max}, nil
+}
+
+func (r uidRange) contains(uid int64) bool {
+	return uid >= r.min && uid <= r.max
+}
+
+func (r uidRange) String() string {
+	return fmt.Sprintf("%d-%d", r.min, r.max)
+}
+
+// getPodViolations returns the pod level settings of a pod spec which are not allowed by the restricted profile.
+func getPodViolations(podSpec map[string]interface{}, uids uidRange) []string {
+	var violations []string
+
+	for _, field := range []string{"hostNetwork", "hostPID", "hostIPC"} {
+		if enabled, _, _ := unstructured.NestedBool(podSpec, field); enabled {
+			violations = append(violations, fmt.Sprintf("%s is enabled", field))
+		}
+	}
+
+	for _, volume := range nestedMaps(podSpec, "volumes") {
+		if hostPath := nestedName(volume, "hostPath", "path"); len(hostPath) > 0 {
+			violations = append(violations, fmt.Sprintf("volume %s mounts host path %s", nestedName(volume, "name"), hostPath))
+		}
+	}
+
+	if uid, found, _ := unstructured.NestedInt64(podSpec, "securityContext", "runAsUser"); found && !uids.contains(uid) {
+		violations = append(violations, fmt.Sprintf("runAsUser %d is outside the allowed range %s", uid, uids))
+	}
+
+	return violations
+}
+
+// getContainerViolations returns the settings of a container which are not allowed by the restricted profile, taking
+// into account the settings inherited from the pod security context.
+func getContainerViolations(podSpec map[string]interface{}, container map[string]interface{}, uids uidRange) []string {
+	var violations []string
+
+	if privileged, _, _ := unstructured.NestedBool(container, "securityContext", "privileged"); privileged {
+		violations = append(violations, "privileged is enabled")
+	}
+
+	if capabilities, _, _ := unstructured.NestedStringSlice(container, "securityContext", "capabilities", "add"); len(capabilities) > 0 {
+		violations = append(violations, fmt.Sprintf("capabilities are added : %s", strings.Join(capabilities, ", ")))
+	}
+
+	if escalation, found, _ := unstructured.NestedBool(container, "securityContext", "allowPrivilegeEscalation"); !found || escalation {
+		violations = append(violations, "allowPrivilegeEscalation is not set to false")
+	}
+
+	runAsNonRoot, found, _ := unstructured.NestedBool(container, "securityContext", "runAsNonRoot")
+	if !found {
+		runAsNonRoot, _, _ = unstructured.NestedBool(podSpec, "securityContext", "runAsNonRoot")
+	}
+	if !runAsNonRoot {
+		violations = append(violations, "runAsNonRoot is not set to true")
+	}
+
+	if uid, found, _ := unstructured.NestedInt64(container, "securityContext", "runAsUser"); found && !uids.contains(uid) {
+		violations = append(violations, fmt.Sprintf("runAsUser %d is outside the allowed range %s", uid, uids))
+	}
+
+	return violations
+}
+
+// RestrictedPodSecurity verifies every pod spec rendered by the chart complies with the OpenShift restricted SCC and
+// the Kubernetes Pod Security Standards restricted profile. Violations are reported per workload and per container.
+func RestrictedPodSecurity(opts *CheckOptions) (Result, error) {
+	_, _, err := LoadChartFromURI(opts.URI)
+	if err != nil {
+		return Result{}, err
+	}
+
+	configRange := opts.ViperConfig.GetString(PodSecurityUIDRangeConfigName)
+	if len(configRange) == 0 {
+		configRange = defaultUIDRange
+	}
+	uids, err := parseUIDRange(configRange)
+	if err != nil {
+		return NewResult(false, err.Error()), nil
+	}
+
+	objects, err := getRenderedObjects(opts.URI, opts.Values)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s : Failed to render chart : %v", PodSecurityCheckFailed, err)), nil
+	}
+
+	r := NewResult(true, "")
+	for _, object := range objects {
+		fields, ok := podSpecPaths[object.GetKind()]
+		if !ok {
+			continue
+		}
+		podSpec, found, _ := unstructured.NestedMap(object.Object, fields...)
+		if !found {
+			continue
+		}
+		workload := fmt.Sprintf("%s/%s (%s)", object.GetKind(), object.GetName(), object.Source)
+
+		for _, violation := range getPodViolations(podSpec, uids) {
+			r.AddFinding(newObjectFinding(ErrorFindingSeverity, fmt.Sprintf("%s : %s : %s", PodSecurityViolation, workload, violation), object,
+				podSecurityRemediation))
+		}
+
+		containers := append(nestedMaps(podSpec, "initContainers"), nestedMaps(podSpec, "containers")...)
+		for _, container := range containers {
+			for _, violation := range getContainerViolations(podSpec, container, uids) {
+				r.AddFinding(newObjectFinding(ErrorFindingSeverity, fmt.Sprintf("%s : %s : container %s : %s", PodSecurityViolation, workload, nestedName(container, "name"), violation), object,
+					podSecurityRemediation))
+			}
+		}
+	}
+
+	if r.Ok {
+		r.SetResult(true, PodSecurityRestricted)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+func TestRestrictedPodSecurity(t *testing.T) {
+	type testCase struct {
+		description string
+		uri         string
+		values      map[string]interface{}
+		uidRange    string
+		reason      string
+	}
+
+	positiveTestCases := []testCase{
+		{description: "chart complying with the restricted profile", uri: "chart-0.1.0-v3.restricted.tgz"},
+		{
+			description: "chart running as a user in the allowed range",
+			uri:         "chart-0.1.0-v3.restricted.tgz",
+			values:      map[string]interface{}{"podSecurityContext": map[string]interface{}{"runAsUser": 1000650001}},
+			uidRange:    "1000650000-1000659999",
+		},
+	}
+
+	for _, tc := range positiveTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			config.Set(PodSecurityUIDRangeConfigName, tc.uidRange)
+			r, err := RestrictedPodSecurity(&CheckOptions{URI: tc.uri, Values: tc.values, ViperConfig: config, HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.True(t, r.Ok)
+			require.Equal(t, PodSecurityRestricted, r.Reason)
+		})
+	}
+
+	negativeTestCases := []testCase{
+		{
+			description: "chart with privileged pods",
+			uri:         "chart-0.1.0-v3.restricted.tgz",
+			values: map[string]interface{}{
+				"hostNetwork":        true,
+				"hostPathVolumes":    []interface{}{map[string]interface{}{"name": "data", "path": "/data"}},
+				"podSecurityContext": map[string]interface{}{"runAsUser": 0},
+				"securityContext": map[string]interface{}{
+					"privileged":               true,
+					"allowPrivilegeEscalation": true,
+					"capabilities":             map[string]interface{}{"add": []interface{}{"NET_ADMIN"}},
+				},
+			},
+			reason: PodSecurityViolation + " : Deployment/testRelease-chart (chart/templates/deployment.yaml) : hostNetwork is enabled\n" +
+				PodSecurityViolation + " : Deployment/testRelease-chart (chart/templates/deployment.yaml) : volume data mounts host path /data\n" +
+				PodSecurityViolation + " : Deployment/testRelease-chart (chart/templates/deployment.yaml) : runAsUser 0 is outside the allowed range 1000000000-2147483647\n" +
+				PodSecurityViolation + " : Deployment/testRelease-chart (chart/templates/deployment.yaml) : container chart : privileged is enabled\n" +
+				PodSecurityViolation + " : Deployment/testRelease-chart (chart/templates/deployment.yaml) : container chart : capabilities are added : NET_ADMIN\n" +
+				PodSecurityViolation + " : Deployment/testRelease-chart (chart/templates/deployment.yaml) : container chart : allowPrivilegeEscalation is not set to false\n" +
+				PodSecurityViolation + " : Pod/testRelease-chart-test-connection (chart/templates/tests/test-connection.yaml) : runAsUser 0 is outside the allowed range 1000000000-2147483647\n" +
+				PodSecurityViolation + " : Pod/testRelease-chart-test-connection (chart/templates/tests/test-connection.yaml) : container wget : privileged is enabled\n" +
+				PodSecurityViolation + " : Pod/testRelease-chart-test-connection (chart/templates/tests/test-connection.yaml) : container wget : capabilities are added : NET_ADMIN\n" +
+				PodSecurityViolation + " : Pod/testRelease-chart-test-connection (chart/templates/tests/test-connection.yaml) : container wget : allowPrivilegeEscalation is not set to false",
+		},
+		{
+			description: "chart running as a user outside the allowed range",
+			uri:         "chart-0.1.0-v3.restricted.tgz",
+			values:      map[string]interface{}{"podSecurityContext": map[string]interface{}{"runAsUser": 1000}},
+			uidRange:    "1000650000-1000659999",
+			reason: PodSecurityViolation + " : Deployment/testRelease-chart (chart/templates/deployment.yaml) : runAsUser 1000 is outside the allowed range 1000650000-1000659999\n" +
+				PodSecurityViolation + " : Pod/testRelease-chart-test-connection (chart/templates/tests/test-connection.yaml) : runAsUser 1000 is outside the allowed range 1000650000-1000659999",
+		},
+		{
+			description: "invalid uid range",
+			uri:         "chart-0.1.0-v3.restricted.tgz",
+			uidRange:    "1000",
+			reason:      PodSecurityCheckFailed + " : invalid uid range 1000",
+		},
+	}
+
+	for _, tc := range negativeTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := viper.New()
+			config.Set(PodSecurityUIDRangeConfigName, tc.uidRange)
+			r, err := RestrictedPodSecurity(&CheckOptions{URI: tc.uri, Values: tc.values, ViperConfig: config, HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.False(t, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+
+	t.Run("chart without security contexts", func(t *testing.T) {
+		r, err := RestrictedPodSecurity(&CheckOptions{URI: "chart-0.1.0-v3.valid.tgz", ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+		require.NoError(t, err)
+		require.NotNil(t, r)
+		require.False(t, r.Ok)
+		require.Contains(t, r.Reason, PodSecurityViolation+" : Deployment/testRelease-chart (chart/templates/deployment.yaml) : container chart : allowPrivilegeEscalation is not set to false")
+		require.Contains(t, r.Reason, PodSecurityViolation+" : Deployment/testRelease-chart (chart/templates/deployment.yaml) : container chart : runAsNonRoot is not set to true")
+		require.Contains(t, r.Reason, PodSecurityViolation+" : Pod/testRelease-chart-test-connection (chart/templates/tests/test-connection.yaml) : container wget : runAsNonRoot is not set to true")
+	})
+}
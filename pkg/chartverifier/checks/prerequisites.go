@@ -0,0 +1,294 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// PrerequisitesAnnotation lists the objects a chart expects to exist before it is installed, for example
+	// "Secret/pull-secret, StorageClass/fast, Certificate.cert-manager.io". Objects are given as Kind/name, the name
+	// can be a pattern such as "Secret/*-tls", and kinds provided by a CRD are given as Kind.group.
+	PrerequisitesAnnotation = "charts.openshift.io/prerequisites"
+)
+
+// podSpecPaths contains the path to the pod spec of each workload kind.
+var podSpecPaths = map[string][]string{
+	"Pod":                   {"spec"},
+	"Deployment":            {"spec", "template", "spec"},
+	"DeploymentConfig":      {"spec", "template", "spec"},
+	"StatefulSet":           {"spec", "template", "spec"},
+	"DaemonSet":             {"spec", "template", "spec"},
+	"ReplicaSet":            {"spec", "template", "spec"},
+	"ReplicationController": {"spec", "template", "spec"},
+	"Job":                   {"spec", "template", "spec"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// prerequisite is an object referenced by a rendered resource.
+type prerequisite struct {
+	// Name identifies the object, as Kind/name or as Kind.group for kinds provided by a CRD.
+	Name string
+	// Source is the chart file of the resource referencing the object.
+	Source string
+}
+
+// getDeclaredPrerequisites returns the prerequisites declared in the chart's annotations.
+func getDeclaredPrerequisites(annotations map[string]string) []string {
+	return strings.FieldsFunc(annotations[PrerequisitesAnnotation], func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\t'
+	})
+}
+
+// isDeclaredPrerequisite returns whether the prerequisite matches one of the declared prerequisites.
+func isDeclaredPrerequisite(name string, declared []string) bool {
+	for _, pattern := range declared {
+		if match, err := path.Match(pattern, name); err == nil && match {
+			return true
+		}
+	}
+	return false
+}
+
+// nestedName returns the string found at the given fields of a map, or the empty string.
+func nestedName(obj map[string]interface{}, fields ...string) string {
+	name, _, _ := unstructured.NestedString(obj, fields...)
+	return name
+}
+
+// nestedMaps returns the maps found in the slice at the given fields of a map.
+func nestedMaps(obj map[string]interface{}, fields ...string) []map[string]interface{} {
+	slice, _, _ := unstructured.NestedSlice(obj, fields...)
+	var maps []map[string]interface{}
+	for _, item := range slice {
+		if m, ok := item.(map[string]interface{}); ok {
+			maps = append(maps, m)
+		}
+	}
+	return maps
+}
+
+// isOptionalReference returns whether a reference has been marked as optional.
+func isOptionalReference(ref map[string]interface{}) bool {
+	optional, _, _ := unstructured.NestedBool(ref, "optional")
+	return optional
+}
+
+// getPodSpecReferences returns the objects referenced by a pod spec, as Kind/name.
+func getPodSpecReferences(podSpec map[string]interface{}) []string {
+	var refs []string
+	add := func(kind, name string) {
+		if len(name) > 0 {
+			refs = append(refs, kind+"/"+name)
+		}
+	}
+
+	serviceAccount := nestedName(podSpec, "serviceAccountName")
+	if len(serviceAccount) == 0 {
+		serviceAccount = nestedName(podSpec, "serviceAccount")
+	}
+	if serviceAccount != "default" {
+		add("ServiceAccount", serviceAccount)
+	}
+
+	for _, secret := range nestedMaps(podSpec, "imagePullSecrets") {
+		add("Secret", nestedName(secret, "name"))
+	}
+
+	for _, volume := range nestedMaps(podSpec, "volumes") {
+		if secret, found, _ := unstructured.NestedMap(volume, "secret"); found && !isOptionalReference(secret) {
+			add("Secret", nestedName(secret, "secretName"))
+		}
+		if configMap, found, _ := unstructured.NestedMap(volume, "configMap"); found && !isOptionalReference(configMap) {
+			add("ConfigMap", nestedName(configMap, "name"))
+		}
+		add("PersistentVolumeClaim", nestedName(volume, "persistentVolumeClaim", "claimName"))
+		for _, source := range nestedMaps(volume, "projected", "sources") {
+			if secret, found, _ := unstructured.NestedMap(source, "secret"); found && !isOptionalReference(secret) {
+				add("Secret", nestedName(secret, "name"))
+			}
+			if configMap, found, _ := unstructured.NestedMap(source, "configMap"); found && !isOptionalReference(configMap) {
+				add("ConfigMap", nestedName(configMap, "name"))
+			}
+		}
+	}
+
+	containers := append(nestedMaps(podSpec, "initContainers"), nestedMaps(podSpec, "containers")...)
+	for _, container := range containers {
+		for _, env := range nestedMaps(container, "env") {
+			if ref, found, _ := unstructured.NestedMap(env, "valueFrom", "secretKeyRef"); found && !isOptionalReference(ref) {
+				add("Secret", nestedName(ref, "name"))
+			}
+			if ref, found, _ := unstructured.NestedMap(env, "valueFrom", "configMapKeyRef"); found && !isOptionalReference(ref) {
+				add("ConfigMap", nestedName(ref, "name"))
+			}
+		}
+		for _, envFrom := range nestedMaps(container, "envFrom") {
+			if ref, found, _ := unstructured.NestedMap(envFrom, "secretRef"); found && !isOptionalReference(ref) {
+				add("Secret", nestedName(ref, "name"))
+			}
+			if ref, found, _ := unstructured.NestedMap(envFrom, "configMapRef"); found && !isOptionalReference(ref) {
+				add("ConfigMap", nestedName(ref, "name"))
+			}
+		}
+	}
+
+	return refs
+}
+
+// getObjectReferences returns the objects referenced by a rendered resource, as Kind/name.
+func getObjectReferences(object renderedObject) []string {
+	var refs []string
+
+	if fields, ok := podSpecPaths[object.GetKind()]; ok {
+		if podSpec, found, _ := unstructured.NestedMap(object.Object, fields...); found {
+			refs = append(refs, getPodSpecReferences(podSpec)...)
+		}
+	}
+
+	switch object.GetKind() {
+	case "PersistentVolumeClaim":
+		if storageClass := nestedName(object.Object, "spec", "storageClassName"); len(storageClass) > 0 {
+			refs = append(refs, "StorageClass/"+storageClass)
+		}
+	case "StatefulSet":
+		for _, claim := range nestedMaps(object.Object, "spec", "volumeClaimTemplates") {
+			if storageClass := nestedName(claim, "spec", "storageClassName"); len(storageClass) > 0 {
+				refs = append(refs, "StorageClass/"+storageClass)
+			}
+		}
+	case "ServiceAccount":
+		for _, secret := range nestedMaps(object.Object, "imagePullSecrets") {
+			if name := nestedName(secret, "name"); len(name) > 0 {
+				refs = append(refs, "Secret/"+name)
+			}
+		}
+	case "Ingress":
+		for _, tls := range nestedMaps(object.Object, "spec", "tls") {
+			if name := nestedName(tls, "secretName"); len(name) > 0 {
+				refs = append(refs, "Secret/"+name)
+			}
+		}
+	}
+
+	return refs
+}
+
+// getCRDGroupKinds returns the kinds defined by the chart's own CRDs, as Kind.group.
+func getCRDGroupKinds(objects []renderedObject) map[string]bool {
+	kinds := make(map[string]bool)
+	for _, object := range objects {
+		if object.GetKind() != "CustomResourceDefinition" {
+			continue
+		}
+		group := nestedName(object.Object, "spec", "group")
+		kind := nestedName(object.Object, "spec", "names", "kind")
+		kinds[kind+"."+group] = true
+	}
+	return kinds
+}
+
+// getPrerequisites returns the objects referenced by the rendered resources which are not created by the chart, and
+// the kinds which are neither served by OpenShift nor defined by the chart's own CRDs.
+func getPrerequisites(objects []renderedObject, dictionaries []*apiDictionary) []prerequisite {
+	created := make(map[string]bool)
+	for _, object := range objects {
+		created[object.GetKind()+"/"+object.GetName()] = true
+	}
+	crdKinds := getCRDGroupKinds(objects)
+
+	var prerequisites []prerequisite
+	reported := make(map[string]bool)
+	add := func(name, source string) {
+		if !reported[name] {
+			reported[name] = true
+			prerequisites = append(prerequisites, prerequisite{Name: name, Source: source})
+		}
+	}
+
+	for _, object := range objects {
+		for _, ref := range getObjectReferences(object) {
+			if !created[ref] {
+				add(ref, object.Source)
+			}
+		}
+
+		served := false
+		for _, dictionary := range dictionaries {
+			if dictionary.serves(object.GetAPIVersion(), object.GetKind()) {
+				served = true
+				break
+			}
+		}
+		groupKind := object.GetKind() + "." + apiGroup(object.GetAPIVersion())
+		if !served && !crdKinds[groupKind] {
+			add(groupKind, object.Source)
+		}
+	}
+
+	return prerequisites
+}
+
+// CanBeInstalledWithoutManualPreRequisites verifies every object referenced by the resources the chart renders, and
+// every custom resource kind it uses, is either created by the chart or declared in the chart's
+// charts.openshift.io/prerequisites annotation.
+func CanBeInstalledWithoutManualPreRequisites(opts *CheckOptions) (Result, error) {
+	c, _, err := LoadChartFromURI(opts.URI)
+	if err != nil {
+		return Result{}, err
+	}
+
+	dictionaries, err := getAPIDictionaries()
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s : %v", PrerequisitesCheckFailed, err)), nil
+	}
+
+	objects, err := getRenderedObjects(opts.URI, opts.Values)
+	if err != nil {
+		return NewResult(false, fmt.Sprintf("%s : Failed to render chart : %v", PrerequisitesCheckFailed, err)), nil
+	}
+
+	declared := getDeclaredPrerequisites(c.Metadata.Annotations)
+
+	r := NewResult(true, "")
+	for _, p := range getPrerequisites(objects, dictionaries) {
+		if !isDeclaredPrerequisite(p.Name, declared) {
+			finding := Finding{
+				Severity:    ErrorFindingSeverity,
+				Message:     fmt.Sprintf("%s : %s (%s)", PrerequisiteNotDeclared, p.Name, p.Source),
+				Path:        p.Source,
+				Kind:        p.Name,
+				Remediation: fmt.Sprintf("declare %s in the %s annotation, or create it in the chart", p.Name, PrerequisitesAnnotation),
+			}
+			if parts := strings.SplitN(p.Name, "/", 2); len(parts) == 2 {
+				finding.Kind, finding.Name = parts[0], parts[1]
+			}
+			r.AddFinding(finding)
+		}
+	}
+
+	if r.Ok {
+		r.SetResult(true, PrerequisitesDeclared)
+	}
+
+	return r, nil
+}
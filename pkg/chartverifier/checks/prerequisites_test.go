@@ -0,0 +1,96 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+func TestCanBeInstalledWithoutManualPreRequisites(t *testing.T) {
+	type testCase struct {
+		description string
+		uri         string
+		values      map[string]interface{}
+		reason      string
+	}
+
+	positiveTestCases := []testCase{
+		{description: "chart creating the objects it references", uri: "chart-0.1.0-v3.valid.tgz"},
+		{
+			description: "chart with declared prerequisites",
+			uri:         "chart-0.1.0-v3.prerequisites.tgz",
+			values:      map[string]interface{}{"persistence": map[string]interface{}{"enabled": false}},
+		},
+	}
+
+	for _, tc := range positiveTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r, err := CanBeInstalledWithoutManualPreRequisites(&CheckOptions{URI: tc.uri, Values: tc.values, ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.True(t, r.Ok)
+			require.Equal(t, PrerequisitesDeclared, r.Reason)
+		})
+	}
+
+	negativeTestCases := []testCase{
+		{
+			description: "chart referencing an undeclared pull secret",
+			uri:         "chart-0.1.0-v3.valid.tgz",
+			values:      map[string]interface{}{"imagePullSecrets": []interface{}{map[string]interface{}{"name": "pull-secret"}}},
+			reason:      PrerequisiteNotDeclared + " : Secret/pull-secret (chart/templates/deployment.yaml)",
+		},
+		{
+			description: "chart referencing an undeclared service account",
+			uri:         "chart-0.1.0-v3.valid.tgz",
+			values:      map[string]interface{}{"serviceAccount": map[string]interface{}{"create": false, "name": "existing"}},
+			reason:      PrerequisiteNotDeclared + " : ServiceAccount/existing (chart/templates/deployment.yaml)",
+		},
+		{
+			description: "chart with declared and undeclared prerequisites",
+			uri:         "chart-0.1.0-v3.prerequisites.tgz",
+			reason:      PrerequisiteNotDeclared + " : PersistentVolumeClaim/data (chart/templates/deployment.yaml)",
+		},
+	}
+
+	for _, tc := range negativeTestCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r, err := CanBeInstalledWithoutManualPreRequisites(&CheckOptions{URI: tc.uri, Values: tc.values, ViperConfig: viper.New(), HelmEnvSettings: cli.New()})
+			require.NoError(t, err)
+			require.NotNil(t, r)
+			require.False(t, r.Ok)
+			require.Equal(t, tc.reason, r.Reason)
+		})
+	}
+}
+
+func TestIsDeclaredPrerequisite(t *testing.T) {
+	declared := getDeclaredPrerequisites(map[string]string{
+		PrerequisitesAnnotation: "Secret/*-tls, StorageClass/fast,\nCertificate.cert-manager.io",
+	})
+	require.Equal(t, []string{"Secret/*-tls", "StorageClass/fast", "Certificate.cert-manager.io"}, declared)
+
+	require.True(t, isDeclaredPrerequisite("Secret/release-tls", declared))
+	require.True(t, isDeclaredPrerequisite("StorageClass/fast", declared))
+	require.True(t, isDeclaredPrerequisite("Certificate.cert-manager.io", declared))
+	require.False(t, isDeclaredPrerequisite("Secret/release-credentials", declared))
+	require.False(t, isDeclaredPrerequisite("ConfigMap/release-tls", declared))
+}
@@ -0,0 +1,315 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	ctchart "github.com/helm/chart-testing/v3/pkg/chart"
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// PreviousChartUriConfigString is the configuration key of the URI of the chart to upgrade from.
+	PreviousChartUriConfigString string = "previousChartUri"
+	// PreviousChartRepositoryConfigString is the configuration key of the Helm repository or OCI registry the chart to
+	// upgrade from is looked up in, e.g. https://charts.example.com or oci://registry.example.com/charts.
+	PreviousChartRepositoryConfigString string = "previousChartRepository"
+
+	// helmChartLayerMediaType is the media type of the layer holding the chart archive in an OCI artifact.
+	helmChartLayerMediaType = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+	ociManifestMediaType    = "application/vnd.oci.image.manifest.v1+json"
+)
+
+type PreviousChartNotFoundErr string
+
+func (e PreviousChartNotFoundErr) Error() string {
+	return "previous chart version not found: " + string(e)
+}
+
+// getPreviousVersion returns the highest of the versions below the current version, ignoring the versions which do not
+// follow the SemVer spec.
+func getPreviousVersion(current string, versions []string) (string, bool) {
+	currentVersion, err := semver.NewVersion(current)
+	if err != nil {
+		return "", false
+	}
+
+	var previous *semver.Version
+	previousVersion := ""
+	for _, version := range versions {
+		v, err := semver.NewVersion(version)
+		if err != nil || !v.LessThan(currentVersion) {
+			continue
+		}
+		if previous == nil || v.GreaterThan(previous) {
+			previous = v
+			previousVersion = version
+		}
+	}
+	return previousVersion, previous != nil
+}
+
+// fetch retrieves the contents of the given url, which may be a local path.
+func fetch(ctx context.Context, u *url.URL) ([]byte, error) {
+	switch u.Scheme {
+	case "http", "https":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("response code %d getting %s", resp.StatusCode, u.String())
+		}
+		return ioutil.ReadAll(resp.Body)
+	case "file", "":
+		return ioutil.ReadFile(u.Path)
+	default:
+		return nil, errors.Errorf("scheme %q not supported", u.Scheme)
+	}
+}
+
+// getPreviousChartFromIndex looks up the previous version of a chart in the index.yaml of a Helm repository, returning
+// the URI of its archive.
+func getPreviousChartFromIndex(ctx context.Context, repository *url.URL, name, version string) (string, error) {
+	base := *repository
+	base.Path = strings.TrimSuffix(base.Path, "/") + "/"
+
+	indexBytes, err := fetch(ctx, base.ResolveReference(&url.URL{Path: "index.yaml"}))
+	if err != nil {
+		return "", err
+	}
+	index := repo.NewIndexFile()
+	if err := yaml.Unmarshal(indexBytes, index); err != nil {
+		return "", fmt.Errorf("index.yaml : %v", err)
+	}
+
+	var versions []string
+	for _, chartVersion := range index.Entries[name] {
+		versions = append(versions, chartVersion.Version)
+	}
+	previousVersion, ok := getPreviousVersion(version, versions)
+	if !ok {
+		return "", PreviousChartNotFoundErr(fmt.Sprintf("no version of %s below %s in %s", name, version, repository))
+	}
+
+	chartVersion, err := index.Get(name, previousVersion)
+	if err != nil {
+		return "", err
+	}
+	if len(chartVersion.URLs) == 0 {
+		return "", fmt.Errorf("index.yaml : no url for %s %s", name, previousVersion)
+	}
+	chartUrl, err := url.Parse(chartVersion.URLs[0])
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(chartUrl).String(), nil
+}
+
+// ociGet retrieves the given url from an OCI registry, requesting an anonymous token when the registry requires one.
+func ociGet(ctx context.Context, u string, accept string) ([]byte, error) {
+	get := func(token string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", accept)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return http.DefaultClient.Do(req)
+	}
+
+	resp, err := get("")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		token, err := getOCIToken(ctx, challenge)
+		if err != nil {
+			return nil, err
+		}
+		if resp, err = get(token); err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("response code %d getting %s", resp.StatusCode, u)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// getOCIToken requests an anonymous token following the Bearer challenge of a registry.
+func getOCIToken(ctx context.Context, challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", errors.Errorf("authentication not supported: %q", challenge)
+	}
+	params := map[string]string{}
+	for _, match := range regexp.MustCompile(`(\w+)="([^"]*)"`).FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+	tokenUrl, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", errors.Errorf("authentication realm not supported: %q", challenge)
+	}
+	query := tokenUrl.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	tokenUrl.RawQuery = query.Encode()
+
+	body, err := fetch(ctx, tokenUrl)
+	if err != nil {
+		return "", err
+	}
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", err
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
+
+// getPreviousChartFromRegistry looks up the previous version of a chart in an OCI registry, returning its archive.
+func getPreviousChartFromRegistry(ctx context.Context, registry *url.URL, name, version string) (io.Reader, string, error) {
+	repository := strings.Trim(registry.Path, "/")
+	if repository != "" {
+		repository += "/"
+	}
+	repository += name
+	base := fmt.Sprintf("https://%s/v2/%s", registry.Host, repository)
+
+	tagsBytes, err := ociGet(ctx, base+"/tags/list", "application/json")
+	if err != nil {
+		return nil, "", err
+	}
+	tags := struct {
+		Tags []string `json:"tags"`
+	}{}
+	if err := json.Unmarshal(tagsBytes, &tags); err != nil {
+		return nil, "", err
+	}
+	// OCI tags do not allow "+", Helm replaces it with "_" when pushing charts
+	var versions []string
+	for _, tag := range tags.Tags {
+		versions = append(versions, strings.ReplaceAll(tag, "_", "+"))
+	}
+	previousVersion, ok := getPreviousVersion(version, versions)
+	if !ok {
+		return nil, "", PreviousChartNotFoundErr(fmt.Sprintf("no version of %s below %s in %s", name, version, registry))
+	}
+	tag := strings.ReplaceAll(previousVersion, "+", "_")
+
+	manifestBytes, err := ociGet(ctx, base+"/manifests/"+tag, ociManifestMediaType)
+	if err != nil {
+		return nil, "", err
+	}
+	manifest := struct {
+		Layers []struct {
+			MediaType string `json:"mediaType"`
+			Digest    string `json:"digest"`
+		} `json:"layers"`
+	}{}
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, "", err
+	}
+	for _, layer := range manifest.Layers {
+		if layer.MediaType == helmChartLayerMediaType {
+			archive, err := ociGet(ctx, base+"/blobs/"+layer.Digest, helmChartLayerMediaType)
+			if err != nil {
+				return nil, "", err
+			}
+			return bytes.NewReader(archive), fmt.Sprintf("oci://%s/%s:%s", registry.Host, repository, tag), nil
+		}
+	}
+	return nil, "", fmt.Errorf("no chart layer in %s:%s", repository, tag)
+}
+
+// getChartPreviousVersion attempts to retrieve the previous version of the given chart, either from the chart URI
+// given by the user or from the highest version below the chart version in the given Helm repository or OCI registry.
+func getChartPreviousVersion(ctx context.Context, chrt *ctchart.Chart, previousChartUri, previousChartRepository string) (*ctchart.Chart, error) {
+	var uri string
+	switch {
+	case previousChartUri != "":
+		uri = previousChartUri
+	case previousChartRepository != "":
+		repository, err := url.Parse(previousChartRepository)
+		if err != nil {
+			return nil, err
+		}
+		if repository.Scheme == "oci" {
+			archive, ociUri, err := getPreviousChartFromRegistry(ctx, repository, chrt.Yaml().Name, chrt.Yaml().Version)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok, _ := defaultChartCache.Get(ociUri); !ok {
+				previous, err := loader.LoadArchive(archive)
+				if err != nil {
+					return nil, err
+				}
+				if _, err := defaultChartCache.Add(ociUri, previous); err != nil {
+					return nil, err
+				}
+			}
+			uri = ociUri
+		} else if uri, err = getPreviousChartFromIndex(ctx, repository, chrt.Yaml().Name, chrt.Yaml().Version); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, PreviousChartNotFoundErr(fmt.Sprintf("neither %s nor %s is set", PreviousChartUriConfigString, PreviousChartRepositoryConfigString))
+	}
+
+	previousChart, previousPath, err := LoadChartFromURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if previousChart.Name() != chrt.Yaml().Name {
+		return nil, fmt.Errorf("previous chart %s is not a version of %s", previousChart.Name(), chrt.Yaml().Name)
+	}
+	return ctchart.NewChart(filepath.Clean(previousPath))
+}
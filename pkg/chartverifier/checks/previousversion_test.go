@@ -0,0 +1,107 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ctchart "github.com/helm/chart-testing/v3/pkg/chart"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPreviousVersion(t *testing.T) {
+	type testCase struct {
+		description string
+		current     string
+		versions    []string
+		previous    string
+		found       bool
+	}
+
+	testCases := []testCase{
+		{description: "highest version below current", current: "1.2.0", versions: []string{"1.0.0", "1.1.9", "1.2.0", "1.3.0"}, previous: "1.1.9", found: true},
+		{description: "versions in any order", current: "1.2.0", versions: []string{"1.1.9", "0.9.0", "1.1.10"}, previous: "1.1.10", found: true},
+		{description: "prerelease below release", current: "1.2.0", versions: []string{"1.2.0-rc.1", "1.1.0"}, previous: "1.2.0-rc.1", found: true},
+		{description: "invalid versions ignored", current: "1.2.0", versions: []string{"latest", "1.0.0"}, previous: "1.0.0", found: true},
+		{description: "no version below current", current: "1.2.0", versions: []string{"1.2.0", "2.0.0"}, found: false},
+		{description: "invalid current version", current: "latest", versions: []string{"1.0.0"}, found: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			previous, found := getPreviousVersion(tc.current, tc.versions)
+			require.Equal(t, tc.found, found)
+			require.Equal(t, tc.previous, previous)
+		})
+	}
+}
+
+// serveRepository serves a Helm repository whose index lists the given versions of the chart, the 0.0.9-v3.valid
+// version being the chart-0.0.9-v3.valid.tgz archive.
+func serveRepository(t *testing.T, versions ...string) *httptest.Server {
+	index := "apiVersion: v1\nentries:\n  chart:\n"
+	for _, version := range versions {
+		index += fmt.Sprintf("  - apiVersion: v2\n    name: chart\n    version: %s\n    urls:\n    - charts/chart-%s.tgz\n", version, version)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repo/index.yaml", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(index))
+	})
+	mux.Handle("/repo/charts/", http.StripPrefix("/repo/charts/", http.FileServer(http.Dir("."))))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetChartPreviousVersion(t *testing.T) {
+	_, chartPath, err := LoadChartFromURI("chart-0.1.0-v3.valid.tgz")
+	require.NoError(t, err)
+	chrt, err := ctchart.NewChart(chartPath)
+	require.NoError(t, err)
+	previousUri, err := absPathFromSourceFileLocation("chart-0.0.9-v3.valid.tgz")
+	require.NoError(t, err)
+
+	t.Run("Previous chart from the user chart URI", func(t *testing.T) {
+		previous, err := getChartPreviousVersion(context.Background(), chrt, previousUri, "")
+		require.NoError(t, err)
+		require.Equal(t, "0.0.9-v3.valid", previous.Yaml().Version)
+	})
+
+	t.Run("Previous chart from the highest version below the chart version in the repository", func(t *testing.T) {
+		server := serveRepository(t, "0.0.1", "0.0.9-v3.valid", "0.1.0-v3.valid", "0.2.0")
+		previous, err := getChartPreviousVersion(context.Background(), chrt, "", server.URL+"/repo")
+		require.NoError(t, err)
+		require.Equal(t, "0.0.9-v3.valid", previous.Yaml().Version)
+	})
+
+	t.Run("No previous chart when the repository has no version below the chart version", func(t *testing.T) {
+		server := serveRepository(t, "0.1.0-v3.valid", "0.2.0")
+		_, err := getChartPreviousVersion(context.Background(), chrt, "", server.URL+"/repo/")
+		require.Error(t, err)
+		require.IsType(t, PreviousChartNotFoundErr(""), err)
+	})
+
+	t.Run("No previous chart when no source is configured", func(t *testing.T) {
+		_, err := getChartPreviousVersion(context.Background(), chrt, "", "")
+		require.Error(t, err)
+		require.IsType(t, PreviousChartNotFoundErr(""), err)
+	})
+}
@@ -17,8 +17,51 @@
 package checks
 
 import (
+	"context"
+
 	"github.com/spf13/viper"
 	helmcli "helm.sh/helm/v3/pkg/cli"
+
+	"github.com/redhat-certification/chart-verifier/pkg/tool"
+)
+
+type FindingSeverity string
+
+const (
+	ErrorFindingSeverity   FindingSeverity = "error"
+	WarningFindingSeverity FindingSeverity = "warning"
+	InfoFindingSeverity    FindingSeverity = "info"
+)
+
+// Finding is a single issue or observation reported by a check.
+type Finding struct {
+	// Severity of the finding, only error findings fail the check.
+	Severity FindingSeverity `json:"severity" yaml:"severity"`
+	// Message describing the finding, as added to the reason of the result.
+	Message string `json:"message" yaml:"message"`
+	// Path of the chart file, template or value the finding is about.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Kind of the resource the finding is about.
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	// Name of the resource or image the finding is about.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Remediation is a hint on how to address the finding.
+	Remediation string `json:"remediation,omitempty" yaml:"remediation,omitempty"`
+}
+
+// ResultStatus qualifies a result whose outcome is not only decided by Ok.
+type ResultStatus string
+
+const (
+	// CompletedResultStatus is the status of a check which ran to completion, its outcome is decided by Ok.
+	CompletedResultStatus ResultStatus = ""
+	// WarningResultStatus is the status of a successful check reporting advisory issues.
+	WarningResultStatus ResultStatus = "warning"
+	// SkippedResultStatus is the status of a check which could not run, the reason is the cause.
+	SkippedResultStatus ResultStatus = "skipped"
+	// ErrorResultStatus is the status of a check which failed because of the tool or the environment, rather than
+	// because of the chart.
+	ErrorResultStatus ResultStatus = "error"
 )
 
 type Result struct {
@@ -27,6 +70,10 @@ type Result struct {
 	// Reason for the result value.  This is a message indicating
 	// the reason for the value of Ok became true or false.
 	Reason string
+	// Findings contains the structured details of the reason.
+	Findings []Finding
+	// Status qualifies the result, a check which did not complete is never Ok.
+	Status ResultStatus
 }
 
 func NewResult(outcome bool, reason string) Result {
@@ -36,6 +83,33 @@ func NewResult(outcome bool, reason string) Result {
 	return result
 }
 
+// NewSkippedResult returns the result of a check which could not run for the given cause.
+func NewSkippedResult(cause string) Result {
+	return Result{Reason: cause, Status: SkippedResultStatus}
+}
+
+// NewErrorResult returns the result of a check which failed because of the tool or the environment.
+func NewErrorResult(reason string) Result {
+	return Result{Reason: reason, Status: ErrorResultStatus}
+}
+
+// IsWarning returns whether the result is successful but reports advisory issues, either through its status or
+// through warning findings.
+func (r *Result) IsWarning() bool {
+	if !r.Ok || r.Status == SkippedResultStatus || r.Status == ErrorResultStatus {
+		return false
+	}
+	if r.Status == WarningResultStatus {
+		return true
+	}
+	for _, finding := range r.Findings {
+		if finding.Severity == WarningFindingSeverity {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *Result) SetResult(outcome bool, reason string) Result {
 	r.Ok = outcome
 	r.Reason = reason
@@ -51,6 +125,12 @@ func (r *Result) AddResult(outcome bool, reason string) Result {
 	return *r
 }
 
+// AddFinding adds the finding to the result and its message to the reason. Error findings make the result fail.
+func (r *Result) AddFinding(finding Finding) Result {
+	r.Findings = append(r.Findings, finding)
+	return r.AddResult(finding.Severity != ErrorFindingSeverity, finding.Message)
+}
+
 type AnnotationHolder interface {
 	SetCertifiedOpenShiftVersion(version string)
 	GetCertifiedOpenShiftVersionFlag() string
@@ -61,18 +141,28 @@ type CheckName string
 type CheckType string
 
 const (
-	HasReadmeName                  CheckName = "has-readme"
-	IsHelmV3Name                   CheckName = "is-helm-v3"
-	ContainsTestName               CheckName = "contains-test"
-	ContainsValuesName             CheckName = "contains-values"
-	ContainsValuesSchemaName       CheckName = "contains-values-schema"
-	HasKubeversionName             CheckName = "has-kubeversion"
-	NotContainsCRDsName            CheckName = "not-contains-crds"
-	HelmLintName                   CheckName = "helm-lint"
-	NotContainCsiObjectsName       CheckName = "not-contain-csi-objects"
-	ImagesAreCertifiedName         CheckName = "images-are-certified"
-	ChartTestingName               CheckName = "chart-testing"
-	RequiredAnnotationsPresentName CheckName = "required-annotations-present"
+	HasReadmeName                                   CheckName = "has-readme"
+	IsHelmV3Name                                    CheckName = "is-helm-v3"
+	ContainsTestName                                CheckName = "contains-test"
+	ContainsValuesName                              CheckName = "contains-values"
+	ContainsValuesSchemaName                        CheckName = "contains-values-schema"
+	HasKubeversionName                              CheckName = "has-kubeversion"
+	NotContainsCRDsName                             CheckName = "not-contains-crds"
+	HelmLintName                                    CheckName = "helm-lint"
+	NotContainCsiObjectsName                        CheckName = "not-contain-csi-objects"
+	ImagesAreCertifiedName                          CheckName = "images-are-certified"
+	ChartTestingName                                CheckName = "chart-testing"
+	RequiredAnnotationsPresentName                  CheckName = "required-annotations-present"
+	APICompatibilityName                            CheckName = "api-compatibility"
+	NotContainsDeprecatedAPIsName                   CheckName = "not-contains-deprecated-apis"
+	CanBeInstalledWithoutClusterAdminPrivilegesName CheckName = "can-be-installed-without-cluster-admin-privileges"
+	CanBeInstalledWithoutManualPreRequisitesName    CheckName = "can-be-installed-without-manual-pre-requisites"
+	KeywordsAreOpenshiftCategoriesName              CheckName = "keywords-are-openshift-categories"
+	IsCommercialChartName                           CheckName = "is-commercial-chart"
+	IsCommunityChartName                            CheckName = "is-community-chart"
+	NotContainsInfraPluginsAndDriversName           CheckName = "not-contains-infra-plugins-and-drivers"
+	RestrictedPodSecurityName                       CheckName = "restricted-pod-security"
+	ContainersHaveResourcesAndProbesName            CheckName = "containers-have-resources-and-probes"
 )
 
 const (
@@ -89,6 +179,10 @@ type Check struct {
 	CheckId CheckId
 	Type    CheckType
 	Func    CheckFunc
+	// Exclusive indicates the check must not run concurrently with other checks, as checks mutating the cluster must.
+	Exclusive bool
+	// Options contains the check configuration set by the profile, overridden by the user configuration.
+	Options map[string]interface{}
 }
 
 // CheckOptions contains options collected from the environment a check can
@@ -104,6 +198,30 @@ type CheckOptions struct {
 	HelmEnvSettings *helmcli.EnvSettings
 	// AnnotationHolder provides and API to set the OpenShift Version
 	AnnotationHolder AnnotationHolder
+	// Categories contains the OpenShift categories of the catalogue pinned by the profile.
+	Categories []string
+	// ProfileVendorType is the vendor type of the profile in use.
+	ProfileVendorType string
+	// Context is cancelled when the check times out or the verification is interrupted.
+	Context context.Context
+	// KubeOpenShiftVersions are the known OpenShift releases and their Kubernetes versions.
+	KubeOpenShiftVersions tool.KubeOpenShiftVersions
+}
+
+// checkContext returns the context of the check, or a context never cancelled if none was set.
+func checkContext(opts *CheckOptions) context.Context {
+	if opts.Context == nil {
+		return context.Background()
+	}
+	return opts.Context
+}
+
+// kubeOpenShiftVersions returns the Kubernetes-OpenShift versions of the check, or the bundled ones if none were set.
+func kubeOpenShiftVersions(opts *CheckOptions) tool.KubeOpenShiftVersions {
+	if opts.KubeOpenShiftVersions == nil {
+		return tool.DefaultKubeOpenShiftVersions()
+	}
+	return opts.KubeOpenShiftVersions
 }
 
 type CheckFunc func(options *CheckOptions) (Result, error)
@@ -135,3 +253,12 @@ func (r *DefaultRegistry) Add(name CheckName, version string, checkFunc CheckFun
 	(*r)[check.CheckId] = check
 	return r
 }
+
+// AddExclusive adds a check which must not run concurrently with other checks. It is not part of the Registry
+// interface, so the registries implemented outside chart-verifier only add checks which may run concurrently.
+func (r *DefaultRegistry) AddExclusive(name CheckName, version string, checkFunc CheckFunc) Registry {
+
+	check := Check{CheckId: CheckId{Name: name, Version: version}, Func: checkFunc, Exclusive: true}
+	(*r)[check.CheckId] = check
+	return r
+}
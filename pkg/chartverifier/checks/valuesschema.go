@@ -0,0 +1,149 @@
+/*
+ * Copyright 2021 Red Hat
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checks
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	ctchart "github.com/helm/chart-testing/v3/pkg/chart"
+	"github.com/xeipuuv/gojsonschema"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// UserValuesSource names the values set with --chart-set, --chart-values and similar flags in the check reasons.
+const UserValuesSource = "user values"
+
+// valuesSource is a set of values validated against the chart values schema, coalesced with the chart defaults as Helm
+// does on install.
+type valuesSource struct {
+	name   string
+	values map[string]interface{}
+}
+
+// getValuesSources returns the chart default values, the values of each file in the chart ci directory and the values
+// set by the user, if any.
+func getValuesSources(c *chart.Chart, chartPath string, userValues map[string]interface{}) ([]valuesSource, error) {
+	defaults, err := chartutil.CoalesceValues(c, map[string]interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %v", chartutil.ValuesfileName, err)
+	}
+	sources := []valuesSource{{name: chartutil.ValuesfileName, values: defaults}}
+
+	ctChart, err := ctchart.NewChart(chartPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, valuesFile := range ctChart.ValuesFilePathsForCI() {
+		name, err := filepath.Rel(chartPath, valuesFile)
+		if err != nil {
+			name = valuesFile
+		}
+		values, err := chartutil.ReadValuesFile(valuesFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s : %v", name, err)
+		}
+		coalesced, err := chartutil.CoalesceValues(c, values)
+		if err != nil {
+			return nil, fmt.Errorf("%s : %v", name, err)
+		}
+		sources = append(sources, valuesSource{name: name, values: coalesced})
+	}
+
+	if len(userValues) > 0 {
+		coalesced, err := chartutil.CoalesceValues(c, userValues)
+		if err != nil {
+			return nil, fmt.Errorf("%s : %v", UserValuesSource, err)
+		}
+		sources = append(sources, valuesSource{name: UserValuesSource, values: coalesced})
+	}
+
+	return sources, nil
+}
+
+// jsonPointer returns the RFC 6901 JSON pointer of a value validated against the schema, appending the property the
+// error refers to when the error is reported on its parent object.
+func jsonPointer(resultError gojsonschema.ResultError) string {
+	tokens := strings.Split(resultError.Context().String("\x00"), "\x00")[1:]
+	switch resultError.Type() {
+	case "required", "additional_property_not_allowed":
+		if property, ok := resultError.Details()["property"].(string); ok {
+			tokens = append(tokens, property)
+		}
+	}
+
+	pointer := ""
+	for _, token := range tokens {
+		pointer += "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+	}
+	return pointer
+}
+
+// schemaViolation is a value which does not match the values schema.
+type schemaViolation struct {
+	pointer     string
+	description string
+}
+
+// getSchemaViolations validates the values against the schema, returning the violations sorted by JSON pointer.
+func getSchemaViolations(schema *gojsonschema.Schema, values map[string]interface{}) ([]schemaViolation, error) {
+	valuesJSON, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	if string(valuesJSON) == "null" {
+		valuesJSON = []byte("{}")
+	}
+
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(valuesJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []schemaViolation
+	for _, resultError := range result.Errors() {
+		violations = append(violations, schemaViolation{pointer: jsonPointer(resultError), description: resultError.Description()})
+	}
+	sort.Slice(violations, func(i, j int) bool {
+		if violations[i].pointer != violations[j].pointer {
+			return violations[i].pointer < violations[j].pointer
+		}
+		return violations[i].description < violations[j].description
+	})
+	return violations, nil
+}
+
+// getSchemaLaxities returns the reasons the root of a schema accepts values it does not describe.
+func getSchemaLaxities(schemaJSON []byte) []string {
+	root := map[string]interface{}{}
+	if err := json.Unmarshal(schemaJSON, &root); err != nil {
+		return []string{"the root schema is not an object"}
+	}
+
+	var laxities []string
+	if properties, _ := root["properties"].(map[string]interface{}); len(properties) == 0 {
+		laxities = append(laxities, "no properties are defined at the root")
+	}
+	if additionalProperties, ok := root["additionalProperties"].(bool); ok && additionalProperties {
+		laxities = append(laxities, "additionalProperties is true at the root")
+	}
+	return laxities
+}
@@ -17,6 +17,8 @@
 package chartverifier
 
 import (
+	"context"
+
 	"github.com/redhat-certification/chart-verifier/pkg/chartverifier/checks"
 	"github.com/spf13/viper"
 	"helm.sh/helm/v3/pkg/cli"
@@ -32,9 +34,13 @@ type VerifierBuilder interface {
 	SetToolVersion(string) VerifierBuilder
 	SetOpenShiftVersion(string) VerifierBuilder
 	SetSettings(settings *cli.EnvSettings) VerifierBuilder
+	SetParallelism(parallelism int) VerifierBuilder
+	SetSortByName(sortByName bool) VerifierBuilder
+	SetFailFast(failFast bool) VerifierBuilder
+	SetKubeOpenShiftVersions(path string) VerifierBuilder
 	Build() (Verifier, error)
 }
 
 type Verifier interface {
-	Verify(uri string) (*Report, error)
+	Verify(ctx context.Context, uri string) (*Report, error)
 }
@@ -0,0 +1,97 @@
+package profiles
+
+import (
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	CategoriesKind           string = "verifier-categories"
+	DefaultCategoriesVersion string = "v1.0"
+)
+
+// Categories is a versioned catalogue of the OpenShift developer catalog categories.
+type Categories struct {
+	Apiversion string   `json:"apiversion" yaml:"apiversion"`
+	Kind       string   `json:"kind" yaml:"kind"`
+	Version    string   `json:"version" yaml:"version"`
+	Categories []string `json:"categories" yaml:"categories"`
+}
+
+var categoriesList []*Categories
+
+// addCategories adds a catalogue read from the config directory, ignoring catalogues without a valid version.
+func addCategories(categories *Categories) {
+	if semver.IsValid(categories.Version) {
+		categoriesList = append(categoriesList, categories)
+	}
+}
+
+// Categories returns the OpenShift categories pinned by the profile version: the catalogue with the highest version
+// not greater than the profile version, or the default catalogue if there is none.
+func (profile *Profile) Categories() []string {
+	var pinned *Categories
+	for _, categories := range categoriesList {
+		if semver.Compare(semver.MajorMinor(categories.Version), semver.MajorMinor(profile.Version)) > 0 {
+			continue
+		}
+		if pinned == nil || semver.Compare(categories.Version, pinned.Version) > 0 {
+			pinned = categories
+		}
+	}
+	if pinned == nil {
+		pinned = getDefaultCategories()
+	}
+	return pinned.Categories
+}
+
+func getDefaultCategories() *Categories {
+	return &Categories{
+		Apiversion: "v1",
+		Kind:       CategoriesKind,
+		Version:    DefaultCategoriesVersion,
+		Categories: []string{
+			"ai-machine-learning",
+			"big-data",
+			"business-automation",
+			"cicd",
+			"database",
+			"developer-tools",
+			"integration-delivery",
+			"languages",
+			"logging-tracing",
+			"middleware",
+			"modernization-migration",
+			"monitoring",
+			"networking",
+			"openshift-optional",
+			"security",
+			"storage",
+			"streaming-messaging",
+		},
+	}
+}
+
+func readCategories(fileName string) (*Categories, error) {
+
+	categoriesYaml, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	categoriesBytes, err := ioutil.ReadAll(categoriesYaml)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := &Categories{}
+	err = yaml.Unmarshal(categoriesBytes, categories)
+	if err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
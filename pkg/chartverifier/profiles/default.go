@@ -17,7 +17,7 @@ func getDefaultProfile(msg string) *Profile {
 	profile := Profile{}
 
 	profile.Apiversion = "v1"
-	profile.Kind = "verifier-profile"
+	profile.Kind = ProfileKind
 
 	profile.Name = "default-profile"
 	if len(msg) > 0 {
@@ -40,7 +40,7 @@ func getDefaultProfile(msg string) *Profile {
 		{Name: fmt.Sprintf("%s/%s", CheckVersion10, checks.HelmLintName), Type: checks.MandatoryCheckType},
 		{Name: fmt.Sprintf("%s/%s", CheckVersion10, checks.NotContainCsiObjectsName), Type: checks.MandatoryCheckType},
 		{Name: fmt.Sprintf("%s/%s", CheckVersion10, checks.ImagesAreCertifiedName), Type: checks.MandatoryCheckType},
-		{Name: fmt.Sprintf("%s/%s", CheckVersion10, checks.ChartTestingName), Type: checks.MandatoryCheckType},
+		{Name: fmt.Sprintf("%s/%s", CheckVersion10, checks.ChartTestingName), Type: checks.MandatoryCheckType, Options: map[string]interface{}{"timeout": "30m"}},
 		{Name: fmt.Sprintf("%s/%s", CheckVersion10, checks.RequiredAnnotationsPresentName), Type: checks.MandatoryCheckType},
 	}
 
@@ -31,6 +31,8 @@ const (
 
 	VendorTypeDefault      VendorType = "default"
 	VendorTypeNotSpecified VendorType = "vendorTypeNotSpecified"
+
+	ProfileKind string = "verifier-profile"
 )
 
 var profileMap map[VendorType][]*Profile
@@ -56,8 +58,9 @@ type Profile struct {
 }
 
 type Check struct {
-	Name string           `json:"name" yaml:"name"`
-	Type checks.CheckType `json:"type" yaml:"type"`
+	Name    string                 `json:"name" yaml:"name"`
+	Type    checks.CheckType       `json:"type" yaml:"type"`
+	Options map[string]interface{} `json:"options,omitempty" yaml:"options,omitempty"`
 }
 
 type FilteredRegistry map[checks.CheckName]checks.Check
@@ -135,7 +138,11 @@ func getProfiles() {
 		if info != nil {
 			if strings.HasSuffix(info.Name(), ".yaml") {
 				profileRead, err := readProfile(path)
-				if err == nil {
+				if err == nil && profileRead.Kind == CategoriesKind {
+					if categoriesRead, err := readCategories(path); err == nil {
+						addCategories(categoriesRead)
+					}
+				} else if err == nil && profileRead.Kind == ProfileKind {
 					// If version is not valid set to a default version
 					if !semver.IsValid(profileRead.Version) {
 						profileRead.Version = DefaultProfileVersion
@@ -157,11 +164,10 @@ func (profile *Profile) FilterChecks(registry checks.DefaultRegistry) FilteredRe
 	filteredChecks := make(map[checks.CheckName]checks.Check)
 
 	for _, check := range profile.Checks {
-		splitter := regexp.MustCompile(`/`)
-		splitCheck := splitter.Split(check.Name, -1)
-		checkIndex := checks.CheckId{Name: checks.CheckName(splitCheck[1]), Version: splitCheck[0]}
+		checkIndex := check.checkId()
 		if newCheck, ok := registry[checkIndex]; ok {
 			newCheck.Type = check.Type
+			newCheck.Options = check.Options
 			filteredChecks[checkIndex.Name] = newCheck
 		}
 	}
@@ -170,6 +176,22 @@ func (profile *Profile) FilterChecks(registry checks.DefaultRegistry) FilteredRe
 
 }
 
+// CheckNames returns the names of the profile checks, in the order the profile lists them.
+func (profile *Profile) CheckNames() []checks.CheckName {
+	var names []checks.CheckName
+	for _, check := range profile.Checks {
+		names = append(names, check.checkId().Name)
+	}
+	return names
+}
+
+// checkId returns the id of a check from its name in the profile, e.g. v1.0/has-readme.
+func (check *Check) checkId() checks.CheckId {
+	splitter := regexp.MustCompile(`/`)
+	splitCheck := splitter.Split(check.Name, -1)
+	return checks.CheckId{Name: checks.CheckName(splitCheck[1]), Version: splitCheck[0]}
+}
+
 func readProfile(fileName string) (*Profile, error) {
 
 	// Open the yaml file which defines the tests to run
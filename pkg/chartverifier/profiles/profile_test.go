@@ -186,3 +186,25 @@ func CompareCheckMaps(t *testing.T, expectedChecks, filteredChecks FilteredRegis
 		}
 	}
 }
+
+func TestCategories(t *testing.T) {
+
+	t.Run("Categories read from disk should match default categories", func(t *testing.T) {
+		assert.Equal(t, 1, len(categoriesList), "Categories number mismatch")
+		assert.True(t, cmp.Equal(getDefaultCategories(), categoriesList[0]), "categories do not match")
+	})
+
+	t.Run("Categories should be pinned by the profile version", func(t *testing.T) {
+		savedCategoriesList := categoriesList
+		defer func() { categoriesList = savedCategoriesList }()
+
+		addCategories(&Categories{Kind: CategoriesKind, Version: configVersion11, Categories: []string{"category-1.1"}})
+		addCategories(&Categories{Kind: CategoriesKind, Version: "not-a-version", Categories: []string{"invalid"}})
+
+		assert.Equal(t, getDefaultCategories().Categories, (&Profile{Version: configVersion00}).Categories())
+		assert.Equal(t, getDefaultCategories().Categories, (&Profile{Version: configVersion10}).Categories())
+		assert.Equal(t, []string{"category-1.1"}, (&Profile{Version: configVersion11}).Categories())
+		assert.Equal(t, []string{"category-1.1"}, (&Profile{Version: configVersion12}).Categories())
+	})
+
+}
@@ -16,6 +16,7 @@
 package pyxis
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -69,11 +70,28 @@ type ImageReference struct {
 	Sha        string
 }
 
-func GetImageRegistries(repository string) ([]string, error) {
+// UnavailableErr is returned when Pyxis cannot be reached or fails to process a request.
+type UnavailableErr string
+
+func (e UnavailableErr) Error() string {
+	return string(e)
+}
+
+func IsUnavailable(err error) bool {
+	_, ok := err.(UnavailableErr)
+	return ok
+}
+
+// isUnavailableStatus returns whether a response status code indicates Pyxis failed to process the request.
+func isUnavailableStatus(statusCode int) bool {
+	return statusCode >= http.StatusInternalServerError || statusCode == http.StatusTooManyRequests
+}
+
+func GetImageRegistries(ctx context.Context, repository string) ([]string, error) {
 	var err error
 	var registries []string
 
-	req, _ := http.NewRequest("GET", pyxisBaseUrl, nil)
+	req, _ := http.NewRequestWithContext(ctx, "GET", pyxisBaseUrl, nil)
 	queryString := req.URL.Query()
 	queryString.Add("filter", fmt.Sprintf("repository==%s", repository))
 	req.URL.RawQuery = queryString.Encode()
@@ -81,7 +99,7 @@ func GetImageRegistries(repository string) ([]string, error) {
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		err = errors.New(fmt.Sprintf("Error getting repository %s : %v\n", repository, err))
+		err = UnavailableErr(fmt.Sprintf("Error getting repository %s : %v", repository, err))
 	} else {
 		if resp.StatusCode == 200 {
 			defer resp.Body.Close()
@@ -96,6 +114,8 @@ func GetImageRegistries(repository string) ([]string, error) {
 			} else {
 				err = errors.New(fmt.Sprintf("Respository not found: %s", repository))
 			}
+		} else if isUnavailableStatus(resp.StatusCode) {
+			err = UnavailableErr(fmt.Sprintf("Bad response code from Pyxis: %d : %s", resp.StatusCode, req.URL))
 		} else {
 			err = errors.New(fmt.Sprintf("Bad response code from Pyxis: %d : %s", resp.StatusCode, req.URL))
 		}
@@ -104,7 +124,7 @@ func GetImageRegistries(repository string) ([]string, error) {
 	return registries, err
 }
 
-func IsImageInRegistry(imageRef ImageReference) (bool, error) {
+func IsImageInRegistry(ctx context.Context, imageRef ImageReference) (bool, error) {
 
 	var err error
 	found := false
@@ -116,7 +136,7 @@ Loops:
 	for _, registry := range imageRef.Registries {
 
 		requestUrl := fmt.Sprintf("%s/registry/%s/repository/%s/images", pyxisBaseUrl, registry, imageRef.Repository)
-		req, _ := http.NewRequest("GET", requestUrl, nil)
+		req, _ := http.NewRequestWithContext(ctx, "GET", requestUrl, nil)
 		queryString := req.URL.Query()
 		queryString.Add("filter", fmt.Sprintf("repositories=em=(repository==%s;registry==%s)", imageRef.Repository, registry))
 		req.URL.RawQuery = queryString.Encode()
@@ -163,11 +183,13 @@ Loops:
 				} else {
 					err = errors.New(fmt.Sprintf("No images found for Registry/Repository: %s/%s", registry, imageRef.Repository))
 				}
+			} else if isUnavailableStatus(resp.StatusCode) {
+				err = UnavailableErr(fmt.Sprintf("Bad response code %d from pyxis request : %s", resp.StatusCode, requestUrl))
 			} else {
 				err = errors.New(fmt.Sprintf("Bad response code %d from pyxis request : %s", resp.StatusCode, requestUrl))
 			}
 		} else {
-			err = reqErr
+			err = UnavailableErr(reqErr.Error())
 		}
 	}
 	if !found {
@@ -17,8 +17,13 @@
 package pyxis
 
 import (
-	"github.com/stretchr/testify/require"
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
 )
 
 func Test_getImageRegistries(t *testing.T) {
@@ -39,7 +44,7 @@ func Test_getImageRegistries(t *testing.T) {
 
 	for _, tc := range PassTestCases {
 		t.Run(tc.description, func(t *testing.T) {
-			reg, err := GetImageRegistries(tc.repository)
+			reg, err := GetImageRegistries(context.Background(), tc.repository)
 			require.NoError(t, err)
 			require.Equal(t, tc.registry, reg[0])
 		})
@@ -51,7 +56,7 @@ func Test_getImageRegistries(t *testing.T) {
 
 	for _, tc := range FailTestCases {
 		t.Run(tc.description, func(t *testing.T) {
-			reg, err := GetImageRegistries(tc.repository)
+			reg, err := GetImageRegistries(context.Background(), tc.repository)
 			require.Error(t, err)
 			require.Empty(t, reg)
 			require.Contains(t, err.Error(), tc.message)
@@ -79,7 +84,7 @@ func Test_checkImageInRegistry(t *testing.T) {
 
 	for _, tc := range PassTestCases {
 		t.Run(tc.description, func(t *testing.T) {
-			found, err := IsImageInRegistry(tc.imageRef)
+			found, err := IsImageInRegistry(context.Background(), tc.imageRef)
 			require.NoError(t, err)
 			require.True(t, found)
 		})
@@ -93,10 +98,68 @@ func Test_checkImageInRegistry(t *testing.T) {
 
 	for _, tc := range FailTestCases {
 		t.Run(tc.description, func(t *testing.T) {
-			found, err := IsImageInRegistry(tc.imageRef)
+			found, err := IsImageInRegistry(context.Background(), tc.imageRef)
 			require.Error(t, err)
 			require.False(t, found)
 			require.Contains(t, err.Error(), tc.message)
 		})
 	}
 }
+
+func Test_pyxisUnavailable(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	savedBaseUrl := pyxisBaseUrl
+	pyxisBaseUrl = server.URL
+	defer func() { pyxisBaseUrl = savedBaseUrl }()
+
+	t.Run("Repository lookup reports Pyxis is unavailable", func(t *testing.T) {
+		reg, err := GetImageRegistries(context.Background(), "nginx")
+		require.Error(t, err)
+		require.Empty(t, reg)
+		require.True(t, IsUnavailable(err))
+	})
+
+	t.Run("Image lookup reports Pyxis is unavailable", func(t *testing.T) {
+		found, err := IsImageInRegistry(context.Background(), ImageReference{Registries: []string{"registry.access.redhat.com"}, Repository: "rhel8/nginx-116", Tag: "latest"})
+		require.Error(t, err)
+		require.False(t, found)
+		require.True(t, IsUnavailable(err))
+	})
+
+	t.Run("Pyxis is unavailable when it cannot be reached", func(t *testing.T) {
+		pyxisBaseUrl = "http://127.0.0.1:0"
+		_, err := GetImageRegistries(context.Background(), "nginx")
+		require.Error(t, err)
+		require.True(t, IsUnavailable(err))
+	})
+}
+
+func Test_pyxisCancelled(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	savedBaseUrl := pyxisBaseUrl
+	pyxisBaseUrl = server.URL
+	defer func() { pyxisBaseUrl = savedBaseUrl }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// the requests would hang without the context, as the server never responds
+	_, err := GetImageRegistries(ctx, "nginx")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), context.DeadlineExceeded.Error())
+
+	found, err := IsImageInRegistry(ctx, ImageReference{Registries: []string{"registry.access.redhat.com"}, Repository: "rhel8/nginx-116", Tag: "latest"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), context.DeadlineExceeded.Error())
+	require.False(t, found)
+}
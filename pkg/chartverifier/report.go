@@ -31,6 +31,12 @@ const (
 	FailOutcomeType    OutcomeType = "FAIL"
 	PassOutcomeType    OutcomeType = "PASS"
 	UnknownOutcomeType OutcomeType = "UNKNOWN"
+	// WarnOutcomeType is the outcome of a successful check reporting advisory issues.
+	WarnOutcomeType OutcomeType = "WARN"
+	// SkippedOutcomeType is the outcome of a check which could not run, the reason is the cause.
+	SkippedOutcomeType OutcomeType = "SKIPPED"
+	// ErrorOutcomeType is the outcome of a check which failed because of the tool or the environment.
+	ErrorOutcomeType OutcomeType = "ERROR"
 )
 
 type Report struct {
@@ -68,10 +74,11 @@ type Profile struct {
 }
 
 type CheckReport struct {
-	Check   checks.CheckName `json:"check" yaml:"check"`
-	Type    checks.CheckType `json:"type" yaml:"type"`
-	Outcome OutcomeType      `json:"outcome" yaml:"outcome"`
-	Reason  string           `json:"reason" yaml:"reason"`
+	Check    checks.CheckName `json:"check" yaml:"check"`
+	Type     checks.CheckType `json:"type" yaml:"type"`
+	Outcome  OutcomeType      `json:"outcome" yaml:"outcome"`
+	Reason   string           `json:"reason" yaml:"reason"`
+	Findings []checks.Finding `json:"findings,omitempty" yaml:"findings,omitempty"`
 }
 
 func newReport() Report {
@@ -100,3 +107,26 @@ func (cr *CheckReport) SetResult(outcome bool, reason string) {
 	}
 	cr.Reason = reason
 }
+
+func (cr *CheckReport) SetFindings(findings []checks.Finding) {
+	cr.Findings = findings
+}
+
+func (cr *CheckReport) SetOutcome(outcome OutcomeType) {
+	cr.Outcome = outcome
+}
+
+// getOutcomeType returns the outcome reported for a check result.
+func getOutcomeType(result checks.Result) OutcomeType {
+	switch {
+	case result.Status == checks.SkippedResultStatus:
+		return SkippedOutcomeType
+	case result.Status == checks.ErrorResultStatus:
+		return ErrorOutcomeType
+	case !result.Ok:
+		return FailOutcomeType
+	case result.IsWarning():
+		return WarnOutcomeType
+	}
+	return PassOutcomeType
+}
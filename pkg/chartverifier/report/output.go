@@ -32,5 +32,8 @@ type MetadataReport struct {
 type ResultsReport struct {
 	Passed   string   `json:"passed" yaml:"passed"`
 	Failed   string   `json:"failed" yaml:"failed"`
+	Warned   string   `json:"warned" yaml:"warned"`
+	Skipped  string   `json:"skipped" yaml:"skipped"`
+	Errored  string   `json:"errored" yaml:"errored"`
 	Messages []string `json:"message" yaml:"message"`
 }
@@ -191,6 +191,9 @@ func Results(opts *ReportOptions) (OutputReport, error) {
 
 	passed := 0
 	failed := 0
+	warned := 0
+	skipped := 0
+	errored := 0
 
 	for _, profileCheck := range profile.Checks {
 		if profileCheck.Type == checks.MandatoryCheckType {
@@ -198,13 +201,20 @@ func Results(opts *ReportOptions) (OutputReport, error) {
 			for _, reportCheck := range report.Results {
 				if strings.Compare(profileCheck.Name, string(reportCheck.Check)) == 0 {
 					found = true
-					if reportCheck.Outcome == chartverifier.PassOutcomeType {
+					switch reportCheck.Outcome {
+					case chartverifier.PassOutcomeType:
 						passed++
-					} else {
+					case chartverifier.WarnOutcomeType:
+						warned++
+					case chartverifier.SkippedOutcomeType:
+						skipped++
+						messages = append(messages, fmt.Sprintf("Skipped mandatory check : %s : %s", profileCheck.Name, reportCheck.Reason))
+					case chartverifier.ErrorOutcomeType:
+						errored++
+						messages = append(messages, fmt.Sprintf("Error running mandatory check : %s : %s", profileCheck.Name, reportCheck.Reason))
+					default:
 						failed++
-						// Change multiple line reasons to a single line
-						reason := strings.ReplaceAll(strings.TrimRight(reportCheck.Reason, "\n"), "\n", ", ")
-						messages = append(messages, reason)
+						messages = append(messages, getFailureMessage(reportCheck))
 					}
 					break
 				}
@@ -221,12 +231,31 @@ func Results(opts *ReportOptions) (OutputReport, error) {
 
 	outputReport.ResultsReport.Passed = fmt.Sprintf("%d", passed)
 	outputReport.ResultsReport.Failed = fmt.Sprintf("%d", failed)
+	outputReport.ResultsReport.Warned = fmt.Sprintf("%d", warned)
+	outputReport.ResultsReport.Skipped = fmt.Sprintf("%d", skipped)
+	outputReport.ResultsReport.Errored = fmt.Sprintf("%d", errored)
 	outputReport.ResultsReport.Messages = messages
 
 	return outputReport, nil
 
 }
 
+// getFailureMessage returns the messages of the error findings of a failed check on a single line, or its reason for
+// reports without findings.
+func getFailureMessage(reportCheck *chartverifier.CheckReport) string {
+	var messages []string
+	for _, finding := range reportCheck.Findings {
+		if finding.Severity == checks.ErrorFindingSeverity {
+			messages = append(messages, finding.Message)
+		}
+	}
+	if len(messages) > 0 {
+		return strings.Join(messages, ", ")
+	}
+	// Change multiple line reasons to a single line
+	return strings.ReplaceAll(strings.TrimRight(reportCheck.Reason, "\n"), "\n", ", ")
+}
+
 var loadedReport *reportInfo
 
 type reportInfo struct {
@@ -189,6 +189,24 @@ func TestReports_1_1(t *testing.T) {
 	allsortsTestInfo.expectedReport.MetadataReport = testPartnerMetaDataReport
 	tests = append(tests, allsortsTestInfo)
 
+	withOutcomesTestInfo := testInfo{}
+	withOutcomesTestInfo.path = "testreports/v1.1/reportwithoutcomes.yaml"
+	withOutcomesTestInfo.description = fmt.Sprintf("Version %s test warned, skipped and errored checks report %s", version, withOutcomesTestInfo.path)
+	withOutcomesTestInfo.expectedReport = &OutputReport{}
+	withOutcomesTestInfo.expectedReport.ResultsReport = &ResultsReport{}
+	withOutcomesTestInfo.expectedReport.ResultsReport.Passed = "8"
+	withOutcomesTestInfo.expectedReport.ResultsReport.Failed = "1"
+	withOutcomesTestInfo.expectedReport.ResultsReport.Warned = "1"
+	withOutcomesTestInfo.expectedReport.ResultsReport.Skipped = "1"
+	withOutcomesTestInfo.expectedReport.ResultsReport.Errored = "1"
+	withOutcomesTestInfo.expectedReport.ResultsReport.Messages = []string{
+		"Skipped mandatory check : v1.0/images-are-certified : Images could not be verified, Pyxis is unavailable : nginx:latest : Bad response code from Pyxis: 503",
+		"Error running mandatory check : v1.0/chart-testing : internal error: \"1.23\" not found in Kubernetes-OpenShift version map",
+		"Missing required annotations : charts.openshift.io/name",
+	}
+	withOutcomesTestInfo.expectedReport.MetadataReport = testRedHatMetaDataReport
+	tests = append(tests, withOutcomesTestInfo)
+
 	setBehaviorTestInfo := testInfo{}
 	setBehaviorTestInfo.path = "testreports/v1.1/reportmissingmandatory.yaml"
 	setBehaviorTestInfo.description = fmt.Sprintf("Version %s test set behvaior missing mandatory report %s", version, missingMandatoryTestInfo.path)
@@ -355,14 +373,38 @@ func CompareResults(expected *ResultsReport, result *ResultsReport) bool {
 		fmt.Println(fmt.Sprintf("results failed mistmatch %s : %s", expected.Failed, result.Failed))
 		outcome = false
 	}
-	numMessages, err := strconv.Atoi(result.Failed)
-	if err != nil {
-		fmt.Println(fmt.Sprintf("results failed cannot be converted to int  %s : %v", result.Failed, err))
-		outcome = false
-	} else if len(result.Messages) != numMessages {
+	for _, count := range []struct{ name, expected, result string }{
+		{"warned", expected.Warned, result.Warned},
+		{"skipped", expected.Skipped, result.Skipped},
+		{"errored", expected.Errored, result.Errored},
+	} {
+		expectedCount := count.expected
+		if len(expectedCount) == 0 {
+			expectedCount = "0"
+		}
+		if strings.Compare(expectedCount, count.result) != 0 {
+			fmt.Println(fmt.Sprintf("results %s mistmatch %s : %s", count.name, expectedCount, count.result))
+			outcome = false
+		}
+	}
+	// failed, skipped and errored mandatory checks each have a message
+	numMessages := 0
+	for _, count := range []string{result.Failed, result.Skipped, result.Errored} {
+		num, err := strconv.Atoi(count)
+		if err != nil {
+			fmt.Println(fmt.Sprintf("results count cannot be converted to int  %s : %v", count, err))
+			outcome = false
+		}
+		numMessages += num
+	}
+	if len(result.Messages) != numMessages {
 		fmt.Println(fmt.Sprintf("results number of fails and number of messages mismatch %d : %d", len(result.Messages), numMessages))
 		outcome = false
 	}
+	if len(expected.Messages) > 0 && strings.Join(expected.Messages, "\n") != strings.Join(result.Messages, "\n") {
+		fmt.Println(fmt.Sprintf("results messages mismatch %v : %v", expected.Messages, result.Messages))
+		outcome = false
+	}
 	return outcome
 }
 
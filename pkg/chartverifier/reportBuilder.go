@@ -29,6 +29,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/redhat-certification/chart-verifier/pkg/chartverifier/checks"
@@ -52,6 +53,8 @@ type CheckResult struct {
 }
 
 type reportBuilder struct {
+	// mutex guards the fields set by checks running concurrently, through AddCheck and the annotation holder.
+	mutex                sync.Mutex
 	Chart                *helmchart.Chart
 	Report               Report
 	OCPVersion           string
@@ -65,11 +68,15 @@ func NewReportBuilder() ReportBuilder {
 }
 
 func (r *reportBuilder) SetTestedOpenShiftVersion(version string) ReportBuilder {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 	r.OCPVersion = version
 	return r
 }
 
 func (r *reportBuilder) SetSupportedOpenShiftVersions(versions string) ReportBuilder {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 	r.SupportedOCPVersions = versions
 	return r
 }
@@ -97,12 +104,18 @@ func (r *reportBuilder) SetChart(chart *helmchart.Chart) ReportBuilder {
 }
 
 func (r *reportBuilder) AddCheck(check checks.Check, result checks.Result) ReportBuilder {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 	checkReport := r.Report.AddCheck(check)
 	checkReport.SetResult(result.Ok, result.Reason)
+	checkReport.SetFindings(result.Findings)
+	checkReport.SetOutcome(getOutcomeType(result))
 	return r
 }
 
 func (r *reportBuilder) Build() (*Report, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 
 	for _, annotation := range profiles.Get().Annotations {
 		switch annotation {
@@ -86,3 +86,28 @@ func TestUrlPackageDigest(t *testing.T) {
 	}
 
 }
+
+func TestGetOutcomeType(t *testing.T) {
+
+	warning := checks.Finding{Severity: checks.WarningFindingSeverity, Message: "API is deprecated"}
+
+	outcomes := []struct {
+		description string
+		result      checks.Result
+		outcome     OutcomeType
+	}{
+		{"Passed check", checks.NewResult(true, "ok"), PassOutcomeType},
+		{"Failed check", checks.NewResult(false, "not ok"), FailOutcomeType},
+		{"Passed check with warning findings", checks.Result{Ok: true, Findings: []checks.Finding{warning}}, WarnOutcomeType},
+		{"Failed check with warning findings", checks.Result{Ok: false, Findings: []checks.Finding{warning}}, FailOutcomeType},
+		{"Passed check with warning status", checks.Result{Ok: true, Status: checks.WarningResultStatus}, WarnOutcomeType},
+		{"Skipped check", checks.NewSkippedResult("no cluster"), SkippedOutcomeType},
+		{"Errored check", checks.NewErrorResult("tool failed"), ErrorOutcomeType},
+	}
+
+	for _, tc := range outcomes {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.outcome, getOutcomeType(tc.result))
+		})
+	}
+}
@@ -17,12 +17,20 @@
 package chartverifier
 
 import (
+	"context"
+	"fmt"
+	"sync"
+
 	"github.com/redhat-certification/chart-verifier/pkg/chartverifier/checks"
 	"github.com/redhat-certification/chart-verifier/pkg/chartverifier/profiles"
+	"github.com/redhat-certification/chart-verifier/pkg/tool"
 	"github.com/spf13/viper"
 	helmcli "helm.sh/helm/v3/pkg/cli"
 )
 
+// CheckTimeoutConfigName is the configuration key of the time a check may run, e.g. chart-testing.timeout=30m.
+const CheckTimeoutConfigName = "timeout"
+
 type CheckNotFoundErr string
 
 func (e CheckNotFoundErr) Error() string {
@@ -57,25 +65,42 @@ func (holder *AnnotationHolder) SetSupportedOpenShiftVersions(versions string) {
 }
 
 type verifier struct {
-	config           *viper.Viper
-	registry         checks.Registry
-	requiredChecks   []checks.Check
-	settings         *helmcli.EnvSettings
-	toolVersion      string
-	profile          *profiles.Profile
-	openshiftVersion string
-	values           map[string]interface{}
+	config                *viper.Viper
+	registry              checks.Registry
+	requiredChecks        []checks.Check
+	settings              *helmcli.EnvSettings
+	toolVersion           string
+	profile               *profiles.Profile
+	openshiftVersion      string
+	values                map[string]interface{}
+	parallelism           int
+	failFast              bool
+	kubeOpenShiftVersions tool.KubeOpenShiftVersions
 }
 
-func (c *verifier) subConfig(name string) *viper.Viper {
-	if sub := c.config.Sub(name); sub == nil {
-		return viper.New()
-	} else {
-		return sub
+// subConfig returns the configuration of a check: the options set by the profile, overridden by the user configuration.
+func (c *verifier) subConfig(check checks.Check) *viper.Viper {
+	config := viper.New()
+	for key, value := range check.Options {
+		config.SetDefault(key, value)
+	}
+	if sub := c.config.Sub(string(check.CheckId.Name)); sub != nil {
+		for _, key := range sub.AllKeys() {
+			config.Set(key, sub.Get(key))
+		}
 	}
+	return config
+}
+
+// checkRun is a required check and the outcome of its execution.
+type checkRun struct {
+	check  checks.Check
+	result checks.Result
+	err    error
+	ran    bool
 }
 
-func (c *verifier) Verify(uri string) (*Report, error) {
+func (c *verifier) Verify(ctx context.Context, uri string) (*Report, error) {
 
 	chrt, _, err := checks.LoadChartFromURI(uri)
 	if err != nil {
@@ -88,28 +113,120 @@ func (c *verifier) Verify(uri string) (*Report, error) {
 		SetChart(chrt).
 		SetProfile(c.profile.Vendor, c.profile.Version)
 
-	for _, check := range c.requiredChecks {
-
+	runs := make([]checkRun, len(c.requiredChecks))
+	for i, check := range c.requiredChecks {
 		if check.Func == nil {
 			return nil, CheckNotFoundErr(check.CheckId.Name)
 		}
-		holder := AnnotationHolder{Holder: result,
-			CertifiedOpenShiftVersionFlag: c.openshiftVersion}
-
-		r, checkErr := check.Func(&checks.CheckOptions{
-			HelmEnvSettings:  c.settings,
-			URI:              uri,
-			Values:           c.values,
-			ViperConfig:      c.subConfig(string(check.CheckId.Name)),
-			AnnotationHolder: &holder,
-		})
-
-		if checkErr != nil {
-			return nil, NewCheckErr(checkErr)
+		runs[i].check = check
+	}
+
+	parallelism := c.parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	// exclusive checks hold the write lock, so they run once the checks in progress have completed and no other
+	// check starts until they have completed themselves.
+	var exclusive sync.RWMutex
+	pending := make(chan *checkRun)
+	// failed is closed when a check returns an error with fail fast, no check starts afterwards.
+	failed := make(chan struct{})
+	var failOnce sync.Once
+	var workers sync.WaitGroup
+	for w := 0; w < parallelism; w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for run := range pending {
+				// the dispatch may have raced with a failure or an interruption
+				select {
+				case <-failed:
+					continue
+				case <-ctx.Done():
+					continue
+				default:
+				}
+				if run.check.Exclusive {
+					exclusive.Lock()
+				} else {
+					exclusive.RLock()
+				}
+				run.result, run.err = c.runCheck(ctx, uri, run.check, result)
+				run.ran = true
+				if run.err != nil && c.failFast {
+					failOnce.Do(func() { close(failed) })
+				}
+				if run.check.Exclusive {
+					exclusive.Unlock()
+				} else {
+					exclusive.RUnlock()
+				}
+			}
+		}()
+	}
+dispatch:
+	for i := range runs {
+		select {
+		case pending <- &runs[i]:
+		case <-failed:
+			break dispatch
+		case <-ctx.Done():
+			break dispatch
 		}
-		_ = result.AddCheck(check, r)
+	}
+	close(pending)
+	workers.Wait()
+
+	// the checks in progress when interrupted have cleaned up, the verification is not reported
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
+	for _, run := range runs {
+		if !run.ran {
+			continue
+		}
+		if run.err != nil {
+			if c.failFast {
+				return nil, NewCheckErr(run.err)
+			}
+			// the check could not complete, its error is reported rather than failing the verification
+			run.result = checks.NewErrorResult(run.err.Error())
+		}
+		_ = result.AddCheck(run.check, run.result)
 	}
 
 	return result.Build()
 }
+
+// runCheck runs a check, which may set annotations in the report through its annotation holder. The check context is
+// cancelled once its configured timeout has elapsed.
+func (c *verifier) runCheck(ctx context.Context, uri string, check checks.Check, result ReportBuilder) (checks.Result, error) {
+	holder := AnnotationHolder{Holder: result,
+		CertifiedOpenShiftVersionFlag: c.openshiftVersion}
+
+	config := c.subConfig(check)
+	timeout := config.GetDuration(CheckTimeoutConfigName)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	r, err := check.Func(&checks.CheckOptions{
+		HelmEnvSettings:       c.settings,
+		URI:                   uri,
+		Values:                c.values,
+		ViperConfig:           config,
+		AnnotationHolder:      &holder,
+		Categories:            c.profile.Categories(),
+		ProfileVendorType:     string(c.profile.Vendor),
+		Context:               ctx,
+		KubeOpenShiftVersions: c.kubeOpenShiftVersions,
+	})
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %s : %w", timeout, err)
+	}
+	return r, err
+}
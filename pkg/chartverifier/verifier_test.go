@@ -19,7 +19,10 @@ package chartverifier
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/redhat-certification/chart-verifier/pkg/chartverifier/profiles"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/spf13/viper"
@@ -73,12 +76,12 @@ func TestVerifier_Verify(t *testing.T) {
 			requiredChecks: []checks.Check{dummyCheck},
 		}
 
-		r, err := c.Verify(validChartUri)
+		r, err := c.Verify(context.Background(), validChartUri)
 		require.Error(t, err)
 		require.Nil(t, r)
 	})
 
-	t.Run("Should return error if check exists and returns error", func(t *testing.T) {
+	t.Run("Should return error if check exists and returns error with fail fast", func(t *testing.T) {
 		dummyCheck.Func = erroredCheck
 		c := &verifier{
 			settings:       cli.New(),
@@ -86,13 +89,34 @@ func TestVerifier_Verify(t *testing.T) {
 			profile:        profiles.Get(),
 			registry:       checks.NewRegistry().Add(dummyCheck.CheckId.Name, "v1.0", erroredCheck),
 			requiredChecks: []checks.Check{dummyCheck},
+			failFast:       true,
 		}
 
-		r, err := c.Verify(validChartUri)
+		r, err := c.Verify(context.Background(), validChartUri)
 		require.Error(t, err)
 		require.Nil(t, r)
 	})
 
+	t.Run("Result should be error if check exists and returns error", func(t *testing.T) {
+		errored := checks.Check{CheckId: checks.CheckId{Name: "errored-check"}, Func: erroredCheck}
+		positive := checks.Check{CheckId: checks.CheckId{Name: "positive-check"}, Func: positiveCheck}
+		c := &verifier{
+			settings:       cli.New(),
+			config:         viper.New(),
+			profile:        profiles.Get(),
+			registry:       checks.NewRegistry(),
+			requiredChecks: []checks.Check{errored, positive},
+		}
+
+		r, err := c.Verify(context.Background(), validChartUri)
+		require.NoError(t, err)
+		require.NotNil(t, r)
+		require.Len(t, r.Results, 2)
+		require.Equal(t, ErrorOutcomeType, r.Results[0].Outcome)
+		require.Equal(t, "artificial error", r.Results[0].Reason)
+		require.Equal(t, PassOutcomeType, r.Results[1].Outcome)
+	})
+
 	t.Run("Result should be negative if check exists and returns negative", func(t *testing.T) {
 		dummyCheck.Func = negativeCheck
 		c := &verifier{
@@ -104,7 +128,7 @@ func TestVerifier_Verify(t *testing.T) {
 			openshiftVersion: "4.9",
 		}
 
-		r, err := c.Verify(validChartUri)
+		r, err := c.Verify(context.Background(), validChartUri)
 		require.NoError(t, err)
 		require.NotNil(t, r)
 		require.False(t, r.isOk())
@@ -120,11 +144,195 @@ func TestVerifier_Verify(t *testing.T) {
 			requiredChecks: []checks.Check{dummyCheck},
 		}
 
-		r, err := c.Verify(validChartUri)
+		r, err := c.Verify(context.Background(), validChartUri)
+		require.NoError(t, err)
+		require.NotNil(t, r)
+		require.True(t, r.isOk())
+	})
+
+	t.Run("Check configuration should merge profile options and user configuration", func(t *testing.T) {
+		optionsCheck := func(opts *checks.CheckOptions) (checks.Result, error) {
+			require.Equal(t, "WARNING", opts.ViperConfig.GetString("failWhen"))
+			require.True(t, opts.ViperConfig.GetBool("strict"))
+			return checks.Result{Ok: true}, nil
+		}
+		dummyCheck.Func = optionsCheck
+		dummyCheck.Options = map[string]interface{}{"failWhen": "ERROR", "strict": true}
+		config := viper.New()
+		config.Set("dummy-check.failWhen", "WARNING")
+		c := &verifier{
+			settings:       cli.New(),
+			config:         config,
+			profile:        profiles.Get(),
+			registry:       checks.NewRegistry().Add(dummyCheck.CheckId.Name, "v1.0", optionsCheck),
+			requiredChecks: []checks.Check{dummyCheck},
+		}
+
+		r, err := c.Verify(context.Background(), validChartUri)
 		require.NoError(t, err)
 		require.NotNil(t, r)
 		require.True(t, r.isOk())
 	})
 
+	t.Run("Report should contain the findings of the check", func(t *testing.T) {
+		finding := checks.Finding{
+			Severity:    checks.ErrorFindingSeverity,
+			Message:     "Image is not Red Hat certified : foo:1.0",
+			Name:        "foo:1.0",
+			Remediation: "use an image certified in a Red Hat registry",
+		}
+		findingCheck := func(_ *checks.CheckOptions) (checks.Result, error) {
+			r := checks.NewResult(true, "")
+			r.AddFinding(finding)
+			return r, nil
+		}
+		dummyCheck.Func = findingCheck
+		dummyCheck.Options = nil
+		c := &verifier{
+			settings:       cli.New(),
+			config:         viper.New(),
+			profile:        profiles.Get(),
+			registry:       checks.NewRegistry().Add(dummyCheck.CheckId.Name, "v1.0", findingCheck),
+			requiredChecks: []checks.Check{dummyCheck},
+		}
+
+		r, err := c.Verify(context.Background(), validChartUri)
+		require.NoError(t, err)
+		require.NotNil(t, r)
+		require.False(t, r.isOk())
+		require.Len(t, r.Results, 1)
+		require.Equal(t, finding.Message, r.Results[0].Reason)
+		require.Equal(t, []checks.Finding{finding}, r.Results[0].Findings)
+	})
+
+	t.Run("Result should be error if check exceeds its timeout", func(t *testing.T) {
+		slowCheck := checks.Check{
+			CheckId: checks.CheckId{Name: "slow-check"},
+			Func: func(opts *checks.CheckOptions) (checks.Result, error) {
+				<-opts.Context.Done()
+				return checks.Result{}, opts.Context.Err()
+			},
+			Options: map[string]interface{}{CheckTimeoutConfigName: "10ms"},
+		}
+		c := &verifier{
+			settings:       cli.New(),
+			config:         viper.New(),
+			profile:        profiles.Get(),
+			registry:       checks.NewRegistry(),
+			requiredChecks: []checks.Check{slowCheck},
+		}
+
+		r, err := c.Verify(context.Background(), validChartUri)
+		require.NoError(t, err)
+		require.NotNil(t, r)
+		require.Len(t, r.Results, 1)
+		require.Equal(t, ErrorOutcomeType, r.Results[0].Outcome)
+		require.True(t, strings.HasPrefix(r.Results[0].Reason, "timed out after 10ms"), r.Results[0].Reason)
+	})
+
+	t.Run("Should return error if verification is cancelled", func(t *testing.T) {
+		verifyCtx, cancelVerify := context.WithCancel(context.Background())
+		cleanedUp := false
+		cancelledCheck := checks.Check{
+			CheckId: checks.CheckId{Name: "cancelled-check"},
+			Func: func(opts *checks.CheckOptions) (checks.Result, error) {
+				cancelVerify()
+				<-opts.Context.Done()
+				cleanedUp = true
+				return checks.Result{}, opts.Context.Err()
+			},
+		}
+		notRunCheck := checks.Check{
+			CheckId: checks.CheckId{Name: "not-run-check"},
+			Func: func(_ *checks.CheckOptions) (checks.Result, error) {
+				t.Error("check started after the verification was cancelled")
+				return checks.Result{}, nil
+			},
+		}
+		c := &verifier{
+			settings:       cli.New(),
+			config:         viper.New(),
+			profile:        profiles.Get(),
+			registry:       checks.NewRegistry(),
+			requiredChecks: []checks.Check{cancelledCheck, notRunCheck},
+		}
+
+		r, err := c.Verify(verifyCtx, validChartUri)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, r)
+		require.True(t, cleanedUp)
+	})
+
+	t.Run("Checks should run concurrently except exclusive ones", func(t *testing.T) {
+		var mutex sync.Mutex
+		running, maxRunning := 0, 0
+		var barrier sync.WaitGroup
+		barrier.Add(2)
+		enter := func() int {
+			mutex.Lock()
+			defer mutex.Unlock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			return running
+		}
+		leave := func() {
+			mutex.Lock()
+			defer mutex.Unlock()
+			running--
+		}
+
+		registry := &checks.DefaultRegistry{}
+		var requiredChecks []checks.Check
+		for i := 0; i < 4; i++ {
+			// the first two checks wait for each other, so they can only complete when running concurrently
+			first := i < 2
+			concurrentCheck := func(_ *checks.CheckOptions) (checks.Result, error) {
+				enter()
+				defer leave()
+				if first {
+					barrier.Done()
+					barrier.Wait()
+				}
+				return checks.NewResult(true, ""), nil
+			}
+			name := checks.CheckName(fmt.Sprintf("concurrent-check-%d", i))
+			registry.Add(name, "v1.0", concurrentCheck)
+			check, _ := registry.Get(checks.CheckId{Name: name, Version: "v1.0"})
+			requiredChecks = append(requiredChecks, check)
+		}
+		exclusiveCheck := func(_ *checks.CheckOptions) (checks.Result, error) {
+			defer leave()
+			if enter() > 1 {
+				return checks.NewResult(false, "exclusive check ran concurrently"), nil
+			}
+			return checks.NewResult(true, ""), nil
+		}
+		registry.AddExclusive("exclusive-check", "v1.0", exclusiveCheck)
+		check, _ := registry.Get(checks.CheckId{Name: "exclusive-check", Version: "v1.0"})
+		require.True(t, check.Exclusive)
+		requiredChecks = append(requiredChecks[:2], append([]checks.Check{check}, requiredChecks[2:]...)...)
+
+		c := &verifier{
+			settings:       cli.New(),
+			config:         viper.New(),
+			profile:        profiles.Get(),
+			registry:       registry,
+			requiredChecks: requiredChecks,
+			parallelism:    3,
+		}
+
+		r, err := c.Verify(context.Background(), validChartUri)
+		require.NoError(t, err)
+		require.NotNil(t, r)
+		require.True(t, r.isOk())
+		require.Len(t, r.Results, len(requiredChecks))
+		for i, check := range requiredChecks {
+			require.Equal(t, checks.CheckName(check.CheckId.Version+"/"+string(check.CheckId.Name)), r.Results[i].Check)
+		}
+		require.Greater(t, maxRunning, 1)
+	})
+
 	cancel()
 }
@@ -18,6 +18,7 @@ package chartverifier
 
 import (
 	"errors"
+	"sort"
 	"strings"
 
 	"github.com/redhat-certification/chart-verifier/pkg/chartverifier/profiles"
@@ -27,26 +28,44 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/redhat-certification/chart-verifier/pkg/chartverifier/checks"
+	"github.com/redhat-certification/chart-verifier/pkg/tool"
 )
 
+// KubeOpenShiftVersionsConfigName is the configuration key of the file replacing the bundled Kubernetes-OpenShift
+// versions.
+const KubeOpenShiftVersionsConfigName = "kubeOpenShiftVersions"
+
 var defaultRegistry checks.Registry
 
 func init() {
-	defaultRegistry = checks.NewRegistry()
+	registry := &checks.DefaultRegistry{}
+	defaultRegistry = registry
 
 	defaultRegistry.Add(checks.HasReadmeName, "v1.0", checks.HasReadme)
 	defaultRegistry.Add(checks.IsHelmV3Name, "v1.0", checks.IsHelmV3)
 	defaultRegistry.Add(checks.ContainsTestName, "v1.0", checks.ContainsTest)
 	defaultRegistry.Add(checks.ContainsValuesName, "v1.0", checks.ContainsValues)
 	defaultRegistry.Add(checks.ContainsValuesSchemaName, "v1.0", checks.ContainsValuesSchema)
+	defaultRegistry.Add(checks.ContainsValuesSchemaName, "v1.1", checks.ContainsValuesSchema_V1_1)
 	defaultRegistry.Add(checks.HasKubeversionName, "v1.0", checks.HasKubeVersion)
 	defaultRegistry.Add(checks.HasKubeversionName, "v1.1", checks.HasKubeVersion_V1_1)
 	defaultRegistry.Add(checks.NotContainsCRDsName, "v1.0", checks.NotContainCRDs)
 	defaultRegistry.Add(checks.HelmLintName, "v1.0", checks.HelmLint)
 	defaultRegistry.Add(checks.NotContainCsiObjectsName, "v1.0", checks.NotContainCSIObjects)
+	defaultRegistry.Add(checks.NotContainCsiObjectsName, "v1.1", checks.NotContainCSIObjects_V1_1)
 	defaultRegistry.Add(checks.ImagesAreCertifiedName, "v1.0", checks.ImagesAreCertified)
-	defaultRegistry.Add(checks.ChartTestingName, "v1.0", checks.ChartTesting)
+	registry.AddExclusive(checks.ChartTestingName, "v1.0", checks.ChartTesting)
 	defaultRegistry.Add(checks.RequiredAnnotationsPresentName, "v1.0", checks.RequiredAnnotationsPresent)
+	defaultRegistry.Add(checks.APICompatibilityName, "v1.0", checks.APICompatibility)
+	defaultRegistry.Add(checks.NotContainsDeprecatedAPIsName, "v1.0", checks.NotContainsDeprecatedAPIs)
+	defaultRegistry.Add(checks.CanBeInstalledWithoutClusterAdminPrivilegesName, "v1.0", checks.CanBeInstalledWithoutClusterAdminPrivileges)
+	defaultRegistry.Add(checks.CanBeInstalledWithoutManualPreRequisitesName, "v1.0", checks.CanBeInstalledWithoutManualPreRequisites)
+	defaultRegistry.Add(checks.KeywordsAreOpenshiftCategoriesName, "v1.0", checks.KeywordsAreOpenshiftCategories)
+	defaultRegistry.Add(checks.IsCommercialChartName, "v1.0", checks.IsCommercialChart)
+	defaultRegistry.Add(checks.IsCommunityChartName, "v1.0", checks.IsCommunityChart)
+	defaultRegistry.Add(checks.NotContainsInfraPluginsAndDriversName, "v1.0", checks.NotContainsInfraPluginsAndDrivers)
+	defaultRegistry.Add(checks.RestrictedPodSecurityName, "v1.0", checks.RestrictedPodSecurity)
+	defaultRegistry.Add(checks.ContainersHaveResourcesAndProbesName, "v1.0", checks.ContainersHaveResourcesAndProbes)
 }
 
 func DefaultRegistry() checks.Registry {
@@ -64,6 +83,10 @@ type verifierBuilder struct {
 	suppportedOpenshiftVersions string
 	values                      map[string]interface{}
 	settings                    *cli.EnvSettings
+	parallelism                 int
+	sortByName                  bool
+	failFast                    bool
+	kubeOpenShiftVersions       string
 }
 
 func (b *verifierBuilder) SetSettings(settings *cli.EnvSettings) VerifierBuilder {
@@ -111,6 +134,26 @@ func (b *verifierBuilder) SetOpenShiftVersion(version string) VerifierBuilder {
 	return b
 }
 
+func (b *verifierBuilder) SetParallelism(parallelism int) VerifierBuilder {
+	b.parallelism = parallelism
+	return b
+}
+
+func (b *verifierBuilder) SetSortByName(sortByName bool) VerifierBuilder {
+	b.sortByName = sortByName
+	return b
+}
+
+func (b *verifierBuilder) SetFailFast(failFast bool) VerifierBuilder {
+	b.failFast = failFast
+	return b
+}
+
+func (b *verifierBuilder) SetKubeOpenShiftVersions(path string) VerifierBuilder {
+	b.kubeOpenShiftVersions = path
+	return b
+}
+
 func (b *verifierBuilder) GetConfig() *viper.Viper {
 	return b.config
 }
@@ -132,26 +175,67 @@ func (b *verifierBuilder) Build() (Verifier, error) {
 		b.settings = cli.New()
 	}
 
-	var requiredChecks []checks.Check
-
-	for _, check := range b.checks {
-		requiredChecks = append(requiredChecks, check)
+	// the file set on the builder takes precedence over the configuration, the bundled versions are used otherwise
+	kubeOpenShiftVersions := b.kubeOpenShiftVersions
+	if kubeOpenShiftVersions == "" {
+		kubeOpenShiftVersions = b.config.GetString(KubeOpenShiftVersionsConfigName)
+	}
+	versions, err := tool.LoadKubeOpenShiftVersions(kubeOpenShiftVersions)
+	if err != nil {
+		return nil, err
 	}
 
 	profile := profiles.Get()
 
+	requiredChecks := orderChecks(b.checks, profile, b.sortByName)
+
 	return &verifier{
-		config:           b.config,
-		registry:         b.registry,
-		requiredChecks:   requiredChecks,
-		settings:         b.settings,
-		toolVersion:      b.toolVersion,
-		profile:          profile,
-		openshiftVersion: b.openshiftVersion,
-		values:           b.values,
+		config:                b.config,
+		registry:              b.registry,
+		requiredChecks:        requiredChecks,
+		settings:              b.settings,
+		toolVersion:           b.toolVersion,
+		profile:               profile,
+		openshiftVersion:      b.openshiftVersion,
+		values:                b.values,
+		parallelism:           b.parallelism,
+		failFast:              b.failFast,
+		kubeOpenShiftVersions: versions,
 	}, nil
 }
 
+// orderChecks returns the checks in the order the profile lists them, or sorted by name. Checks the profile does not
+// list come last, sorted by name.
+func orderChecks(filtered FilteredRegistry, profile *profiles.Profile, sortByName bool) []checks.Check {
+	var names []checks.CheckName
+	if !sortByName {
+		for _, name := range profile.CheckNames() {
+			if _, ok := filtered[name]; ok {
+				names = append(names, name)
+			}
+		}
+	}
+
+	ordered := make(map[checks.CheckName]bool)
+	for _, name := range names {
+		ordered[name] = true
+	}
+	var unordered []checks.CheckName
+	for name := range filtered {
+		if !ordered[name] {
+			unordered = append(unordered, name)
+		}
+	}
+	sort.Slice(unordered, func(i, j int) bool { return unordered[i] < unordered[j] })
+	names = append(names, unordered...)
+
+	var requiredChecks []checks.Check
+	for _, name := range names {
+		requiredChecks = append(requiredChecks, filtered[name])
+	}
+	return requiredChecks
+}
+
 func NewVerifierBuilder() VerifierBuilder {
 	return &verifierBuilder{}
 }
@@ -20,8 +20,12 @@ import (
 	"github.com/redhat-certification/chart-verifier/pkg/chartverifier/checks"
 	"github.com/redhat-certification/chart-verifier/pkg/chartverifier/profiles"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
 	"testing"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 )
 
@@ -55,4 +59,55 @@ func TestCertificationBuilder(t *testing.T) {
 		filteredChecks := profiles.Get().FilterChecks(defaultRegistry.AllChecks())
 		assert.Equal(t, len(profiles.Get().Checks), len(filteredChecks), "Checks mismatch : %d in profile, %d after filtering", len(profiles.Get().Checks), len(filteredChecks))
 	})
+
+	t.Run("Verifier should run the checks in the order of the profile", func(t *testing.T) {
+		filteredChecks := profiles.Get().FilterChecks(DefaultRegistry().AllChecks())
+		filteredChecks["z-unlisted"] = checks.Check{CheckId: checks.CheckId{Name: "z-unlisted"}}
+		filteredChecks["a-unlisted"] = checks.Check{CheckId: checks.CheckId{Name: "a-unlisted"}}
+
+		for i := 0; i < 3; i++ {
+			c, err := NewVerifierBuilder().SetChecks(FilteredRegistry(filteredChecks)).Build()
+			require.NoError(t, err)
+
+			var names []checks.CheckName
+			for _, check := range c.(*verifier).requiredChecks {
+				names = append(names, check.CheckId.Name)
+			}
+			expected := append(profiles.Get().CheckNames(), "a-unlisted", "z-unlisted")
+			require.Equal(t, expected, names)
+		}
+	})
+
+	t.Run("Verifier should run the checks sorted by name", func(t *testing.T) {
+		filteredChecks := profiles.Get().FilterChecks(DefaultRegistry().AllChecks())
+
+		c, err := NewVerifierBuilder().SetChecks(FilteredRegistry(filteredChecks)).SetSortByName(true).Build()
+		require.NoError(t, err)
+
+		names := make([]string, 0)
+		for _, check := range c.(*verifier).requiredChecks {
+			names = append(names, string(check.CheckId.Name))
+		}
+		require.Len(t, names, len(filteredChecks))
+		require.True(t, sort.StringsAreSorted(names))
+	})
+
+	t.Run("Verifier should use the Kubernetes-OpenShift versions of the configuration", func(t *testing.T) {
+		checkMap := FilteredRegistry{"a": checks.Check{CheckId: checks.CheckId{Name: "a"}}}
+		versionsFile := filepath.Join(t.TempDir(), "versions.yaml")
+		require.NoError(t, ioutil.WriteFile(versionsFile, []byte("version: v1\nversions:\n- kubeVersion: \"1.23\"\n  openshiftVersion: \"4.10\"\n  status: full-support\n"), 0644))
+
+		configured, err := NewVerifierBuilder().SetChecks(checkMap).SetConfig(viper.New()).SetOverrides([]string{KubeOpenShiftVersionsConfigName + "=" + versionsFile}).Build()
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"1.23": "4.10"}, configured.(*verifier).kubeOpenShiftVersions.VersionMap())
+
+		_, err = NewVerifierBuilder().SetChecks(checkMap).SetKubeOpenShiftVersions(filepath.Join(t.TempDir(), "missing.yaml")).Build()
+		require.Error(t, err)
+
+		// building another verifier does not change the versions of the existing one
+		bundled, err := NewVerifierBuilder().SetChecks(checkMap).Build()
+		require.NoError(t, err)
+		require.Equal(t, "4.9", bundled.(*verifier).kubeOpenShiftVersions.VersionMap()["1.22"])
+		require.Equal(t, map[string]string{"1.23": "4.10"}, configured.(*verifier).kubeOpenShiftVersions.VersionMap())
+	})
 }
@@ -0,0 +1,176 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// diagnosticsLogLines is the number of lines of each container log collected.
+	diagnosticsLogLines int64 = 200
+	// diagnosticsEvents is the number of most recent events collected.
+	diagnosticsEvents = 50
+	// testHookAnnotation is the Helm annotation of the hooks, including the test pods.
+	testHookAnnotation = "helm.sh/hook"
+)
+
+// PodDiagnostics is the state of a pod of a release.
+type PodDiagnostics struct {
+	Name string
+	// Status summarizes the phase of the pod and the state of its containers.
+	Status string
+	// Logs contains the last lines logged by each container, including init containers, by container name.
+	Logs map[string]string
+}
+
+// Diagnostics is the state of the workloads of a release, collected to debug a failed installation or test.
+type Diagnostics struct {
+	Pods []PodDiagnostics
+	// Deployments summarizes the conditions of each deployment.
+	Deployments []string
+	// Events lists the most recent events, oldest first.
+	Events []string
+}
+
+// isReleasePod returns whether the pod belongs to the release. The test hook pods of a chart do not necessarily have
+// the release label, they are recognized by their name.
+func isReleasePod(pod corev1.Pod, release string, selected map[string]bool) bool {
+	if selected[pod.Name] {
+		return true
+	}
+	hook, ok := pod.Annotations[testHookAnnotation]
+	return ok && strings.Contains(hook, "test") && strings.Contains(pod.Name, release)
+}
+
+// getPodStatus summarizes the phase of the pod and the state of its containers.
+func getPodStatus(pod corev1.Pod) string {
+	status := []string{string(pod.Status.Phase)}
+	containerStatuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, container := range containerStatuses {
+		state := "unknown"
+		switch {
+		case container.State.Waiting != nil:
+			state = fmt.Sprintf("waiting %s %s", container.State.Waiting.Reason, container.State.Waiting.Message)
+		case container.State.Terminated != nil:
+			state = fmt.Sprintf("terminated %s exit code %d %s", container.State.Terminated.Reason, container.State.Terminated.ExitCode, container.State.Terminated.Message)
+		case container.State.Running != nil:
+			state = fmt.Sprintf("running ready=%t", container.Ready)
+		}
+		status = append(status, fmt.Sprintf("%s %s restarts=%d", container.Name, strings.TrimSpace(state), container.RestartCount))
+	}
+	return strings.Join(status, " : ")
+}
+
+// GetDiagnostics collects the state of the pods, including the test hook pods, and deployments of a release, and the
+// most recent events of the namespace. When the selector is empty, every pod and deployment of the namespace is
+// collected.
+func (k Kubectl) GetDiagnostics(context context.Context, namespace, release, selector string) (*Diagnostics, error) {
+	diagnostics := &Diagnostics{}
+
+	selectedPods, err := k.clientset.CoreV1().Pods(namespace).List(context, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	selected := map[string]bool{}
+	for _, pod := range selectedPods.Items {
+		selected[pod.Name] = true
+	}
+	pods := selectedPods
+	if selector != "" {
+		if pods, err = k.clientset.CoreV1().Pods(namespace).List(context, metav1.ListOptions{}); err != nil {
+			return nil, err
+		}
+	}
+	for _, pod := range pods.Items {
+		if !isReleasePod(pod, release, selected) {
+			continue
+		}
+		podDiagnostics := PodDiagnostics{Name: pod.Name, Status: getPodStatus(pod), Logs: map[string]string{}}
+		containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+		for _, container := range containers {
+			tailLines := diagnosticsLogLines
+			logs, err := k.clientset.CoreV1().Pods(namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+				Container: container.Name,
+				TailLines: &tailLines,
+			}).DoRaw(context)
+			if err != nil {
+				podDiagnostics.Logs[container.Name] = fmt.Sprintf("error getting logs: %v", err)
+			} else {
+				podDiagnostics.Logs[container.Name] = string(logs)
+			}
+		}
+		diagnostics.Pods = append(diagnostics.Pods, podDiagnostics)
+	}
+
+	deployments, err := k.clientset.AppsV1().Deployments(namespace).List(context, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments.Items {
+		var conditions []string
+		for _, condition := range deployment.Status.Conditions {
+			conditions = append(conditions, fmt.Sprintf("%s=%s %s %s", condition.Type, condition.Status, condition.Reason, condition.Message))
+		}
+		diagnostics.Deployments = append(diagnostics.Deployments, fmt.Sprintf("%s : %d/%d replicas ready : %s",
+			deployment.Name, deployment.Status.ReadyReplicas, deployment.Status.Replicas, strings.Join(conditions, " : ")))
+	}
+
+	events, err := k.clientset.CoreV1().Events(namespace).List(context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// in a shared namespace, only the events of the objects of the release are relevant
+	var releaseEvents []corev1.Event
+	for _, event := range events.Items {
+		if selector == "" || selected[event.InvolvedObject.Name] || strings.Contains(event.InvolvedObject.Name, release) {
+			releaseEvents = append(releaseEvents, event)
+		}
+	}
+	sort.SliceStable(releaseEvents, func(i, j int) bool {
+		return releaseEvents[i].LastTimestamp.Before(&releaseEvents[j].LastTimestamp)
+	})
+	if len(releaseEvents) > diagnosticsEvents {
+		releaseEvents = releaseEvents[len(releaseEvents)-diagnosticsEvents:]
+	}
+	for _, event := range releaseEvents {
+		diagnostics.Events = append(diagnostics.Events, fmt.Sprintf("%s %s %s/%s : %s",
+			event.Type, event.Reason, event.InvolvedObject.Kind, event.InvolvedObject.Name, strings.TrimSpace(event.Message)))
+	}
+
+	return diagnostics, nil
+}
+
+// WriteFiles writes the diagnostics in the given directory: the pod statuses, deployment conditions and events in
+// pods.txt, deployments.txt and events.txt, and the logs of each container in logs/<pod>_<container>.log.
+func (d *Diagnostics) WriteFiles(dir string) error {
+	logsDir := filepath.Join(dir, "logs")
+	if err := os.MkdirAll(logsDir, 0755); err != nil {
+		return err
+	}
+
+	var pods []string
+	for _, pod := range d.Pods {
+		pods = append(pods, fmt.Sprintf("%s : %s", pod.Name, pod.Status))
+		for container, logs := range pod.Logs {
+			if err := ioutil.WriteFile(filepath.Join(logsDir, fmt.Sprintf("%s_%s.log", pod.Name, container)), []byte(logs), 0644); err != nil {
+				return err
+			}
+		}
+	}
+
+	files := map[string][]string{"pods.txt": pods, "deployments.txt": d.Deployments, "events.txt": d.Events}
+	for name, lines := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package tool
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetDiagnostics(t *testing.T) {
+	namespace := "shared"
+	selector := "app.kubernetes.io/instance=release"
+	now := time.Now()
+
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "release-app", Namespace: namespace, Labels: map[string]string{"app.kubernetes.io/instance": "release"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "app", RestartCount: 3,
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "release-test-connection", Namespace: namespace, Annotations: map[string]string{"helm.sh/hook": "test"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "wget"}}},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodFailed,
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "wget",
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}}}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-app", Namespace: namespace, Labels: map[string]string{"app.kubernetes.io/instance": "other"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "release-app", Namespace: namespace, Labels: map[string]string{"app.kubernetes.io/instance": "release"}},
+			Status: appsv1.DeploymentStatus{
+				Replicas: 1,
+				Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse,
+					Reason: "MinimumReplicasUnavailable", Message: "Deployment does not have minimum availability."}},
+			},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "event-2", Namespace: namespace},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "release-app"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+			LastTimestamp:  metav1.NewTime(now),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "event-1", Namespace: namespace},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "release-app"},
+			Type:           corev1.EventTypeNormal,
+			Reason:         "Scheduled",
+			Message:        "Successfully assigned shared/release-app",
+			LastTimestamp:  metav1.NewTime(now.Add(-time.Minute)),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "event-3", Namespace: namespace},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "other-app"},
+			Type:           corev1.EventTypeNormal,
+			Reason:         "Started",
+			LastTimestamp:  metav1.NewTime(now),
+		},
+	)
+	kubectl := Kubectl{clientset: clientset}
+
+	diagnostics, err := kubectl.GetDiagnostics(context.Background(), namespace, "release", selector)
+	require.NoError(t, err)
+
+	require.Len(t, diagnostics.Pods, 2)
+	pods := map[string]PodDiagnostics{}
+	for _, pod := range diagnostics.Pods {
+		pods[pod.Name] = pod
+	}
+	require.Equal(t, "Pending : app waiting CrashLoopBackOff restarts=3", pods["release-app"].Status)
+	require.Equal(t, "fake logs", pods["release-app"].Logs["app"])
+	require.Equal(t, "Failed : wget terminated Error exit code 1 restarts=0", pods["release-test-connection"].Status)
+	require.Contains(t, pods["release-test-connection"].Logs, "wget")
+
+	require.Equal(t, []string{"release-app : 0/1 replicas ready : Available=False MinimumReplicasUnavailable Deployment does not have minimum availability."},
+		diagnostics.Deployments)
+
+	require.Equal(t, []string{
+		"Normal Scheduled Pod/release-app : Successfully assigned shared/release-app",
+		"Warning BackOff Pod/release-app : Back-off restarting failed container",
+	}, diagnostics.Events)
+
+	dir := t.TempDir()
+	require.NoError(t, diagnostics.WriteFiles(dir))
+	for _, name := range []string{"pods.txt", "deployments.txt", "events.txt", filepath.Join("logs", "release-app_app.log")} {
+		contents, err := ioutil.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		require.NotEmpty(t, contents)
+	}
+}
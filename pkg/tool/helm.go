@@ -1,6 +1,7 @@
 package tool
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
@@ -13,6 +14,18 @@ import (
 	"k8s.io/helm/pkg/strvals"
 )
 
+// defaultTimeout is the time Helm waits for an operation when the context has no deadline.
+// ref: https://helm.sh/docs/helm/helm_install
+const defaultTimeout = 5 * time.Minute
+
+// timeoutFromContext returns the time left before the deadline of the context, or the default timeout.
+func timeoutFromContext(ctx context.Context) time.Duration {
+	if deadline, ok := ctx.Deadline(); ok {
+		return time.Until(deadline)
+	}
+	return defaultTimeout
+}
+
 type Helm struct {
 	config      *action.Configuration
 	envSettings *cli.EnvSettings
@@ -31,15 +44,13 @@ func NewHelm(envSettings *cli.EnvSettings, args map[string]interface{}) (*Helm,
 	return helm, nil
 }
 
-func (h Helm) Install(namespace, chart, release, valuesFile string) error {
+func (h Helm) Install(ctx context.Context, namespace, chart, release, valuesFile string) error {
 	LogInfo(fmt.Sprintf("Execute helm install. namespace: %s, release: %s chart: %s", namespace, release, chart))
 	client := action.NewInstall(h.config)
 	client.Namespace = namespace
 	client.ReleaseName = release
 	client.Wait = true
-	// default timeout duration
-	// ref: https://helm.sh/docs/helm/helm_install
-	client.Timeout = 5 * time.Minute
+	client.Timeout = timeoutFromContext(ctx)
 
 	cp, err := client.ChartPathOptions.LocateChart(chart, h.envSettings)
 	if err != nil {
@@ -86,7 +97,7 @@ func (h Helm) Install(namespace, chart, release, valuesFile string) error {
 	}
 
 	// TODO: support other options if required
-	_, err = client.Run(c, vals)
+	_, err = client.RunWithContext(ctx, c, vals)
 	if err != nil {
 		LogError(fmt.Sprintf("Error running chart install: %v", err))
 		return err
@@ -96,13 +107,25 @@ func (h Helm) Install(namespace, chart, release, valuesFile string) error {
 	return nil
 }
 
-func (h Helm) Test(namespace, release string) error {
+func (h Helm) Test(ctx context.Context, namespace, release string) error {
 	LogInfo(fmt.Sprintf("Execute helm test. namespace: %s, release: %s, args: %+v", namespace, release, h.args))
 	client := action.NewReleaseTesting(h.config)
 	client.Namespace = namespace
-
-	// TODO: support filter and timeout options if required
-	_, err := client.Run(release)
+	client.Timeout = timeoutFromContext(ctx)
+
+	// TODO: support filter options if required
+	// the test action does not take a context, the test pods are left to the cleanup of the release when cancelled
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Run(release)
+		done <- err
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
 	if err != nil {
 		LogError(fmt.Sprintf("Execute helm test. error %v", err))
 		return err
@@ -112,9 +135,10 @@ func (h Helm) Test(namespace, release string) error {
 	return nil
 }
 
-func (h Helm) Uninstall(namespace, release string) error {
+func (h Helm) Uninstall(ctx context.Context, namespace, release string) error {
 	LogInfo(fmt.Sprintf("Execute helm uninstall. namespace: %s, release: %s", namespace, release))
 	client := action.NewUninstall(h.config)
+	client.Timeout = timeoutFromContext(ctx)
 	// TODO: support other options if required
 	_, err := client.Run(release)
 
@@ -127,12 +151,13 @@ func (h Helm) Uninstall(namespace, release string) error {
 	return nil
 }
 
-func (h Helm) Upgrade(namespace, chart, release string) error {
+func (h Helm) Upgrade(ctx context.Context, namespace, chart, release string) error {
 	LogInfo(fmt.Sprintf("Execute helm upgrade. namespace: %s, release: %s chart: %s", namespace, release, chart))
 	client := action.NewUpgrade(h.config)
 	client.Namespace = namespace
 	client.ReuseValues = true
 	client.Wait = true
+	client.Timeout = timeoutFromContext(ctx)
 
 	cp, err := client.ChartPathOptions.LocateChart(chart, h.envSettings)
 	if err != nil {
@@ -155,7 +180,7 @@ func (h Helm) Upgrade(namespace, chart, release string) error {
 	}
 
 	// TODO: support other options if required
-	_, err = client.Run(release, c, vals)
+	_, err = client.RunWithContext(ctx, release, c, vals)
 	if err != nil {
 		LogError(fmt.Sprintf("Error running chart upgrade: %v", err))
 		return err
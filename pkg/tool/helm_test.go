@@ -1,8 +1,10 @@
 package tool
 
 import (
+	"context"
 	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"helm.sh/helm/v3/pkg/action"
@@ -45,7 +47,7 @@ func TestInstall(t *testing.T) {
 				args:        map[string]interface{}{"set": "k8Project=default"},
 				envSettings: &cli.EnvSettings{},
 			}
-			err := helm.Install("default", tt.chartPath, tt.releaseName, "")
+			err := helm.Install(context.Background(), "default", tt.chartPath, tt.releaseName, "")
 			if err == nil {
 				require.Equal(t, tt.expected, "")
 			} else {
@@ -105,7 +107,7 @@ func TestUninstall(t *testing.T) {
 					t.Error(err)
 				}
 			}
-			err := helm.Uninstall("default", tt.release.Name)
+			err := helm.Uninstall(context.Background(), "default", tt.release.Name)
 			if err == nil {
 				require.Equal(t, tt.expected, "")
 			} else {
@@ -175,7 +177,7 @@ func TestUpgrade(t *testing.T) {
 					t.Error(err)
 				}
 			}
-			err := helm.Upgrade("default", tt.chartPath, tt.release.Name)
+			err := helm.Upgrade(context.Background(), "default", tt.chartPath, tt.release.Name)
 			if err == nil {
 				require.Equal(t, tt.expected, "")
 			} else {
@@ -255,7 +257,7 @@ func TestReleaseTesting(t *testing.T) {
 					t.Error(err)
 				}
 			}
-			err := helm.Test("default", tt.release.Name)
+			err := helm.Test(context.Background(), "default", tt.release.Name)
 			if err == nil {
 				require.Equal(t, tt.expected, "")
 			} else {
@@ -264,3 +266,13 @@ func TestReleaseTesting(t *testing.T) {
 		})
 	}
 }
+
+func TestTimeoutFromContext(t *testing.T) {
+	require.Equal(t, defaultTimeout, timeoutFromContext(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	timeout := timeoutFromContext(ctx)
+	require.LessOrEqual(t, timeout, time.Minute)
+	require.Greater(t, timeout, 50*time.Second)
+}
@@ -5,30 +5,25 @@ import (
 	"fmt"
 
 	"helm.sh/helm/v3/pkg/cli"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/kubectl/pkg/scheme"
 )
 
-// Based on https://access.redhat.com/solutions/4870701
-var kubeOpenShiftVersionMap map[string]string = map[string]string{
-	"1.22": "4.9",
-	"1.21": "4.8",
-	"1.20": "4.7",
-	"1.19": "4.6",
-	"1.18": "4.5",
-	"1.17": "4.4",
-	"1.16": "4.3",
-	"1.14": "4.2",
-	"1.13": "4.1",
-}
+// clusterVersionResource is the OpenShift resource describing the version of the cluster, which is named "version".
+var clusterVersionResource = schema.GroupVersionResource{Group: "config.openshift.io", Version: "v1", Resource: "clusterversions"}
 
 type Kubectl struct {
-	clientset kubernetes.Interface
+	clientset     kubernetes.Interface
+	dynamicClient dynamic.Interface
 }
 
 func NewKubectl(kubeConfig clientcmd.ClientConfig) (*Kubectl, error) {
@@ -45,47 +40,83 @@ func NewKubectl(kubeConfig clientcmd.ClientConfig) (*Kubectl, error) {
 	if err != nil {
 		return nil, err
 	}
+	kubectl.dynamicClient, err = dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
 
 	return kubectl, nil
 }
 
-func (k Kubectl) WaitForDeployments(context context.Context, namespace string, selector string) error {
-	deployments, err := k.clientset.AppsV1().Deployments(namespace).List(context, metav1.ListOptions{
-		LabelSelector: selector,
-	})
-	if err != nil {
+func (k Kubectl) DeleteNamespace(context context.Context, namespace string) error {
+	if err := k.clientset.CoreV1().Namespaces().Delete(context, namespace, *metav1.NewDeleteOptions(0)); err != nil {
 		return err
 	}
+	return nil
+}
 
-	for _, deployment := range deployments.Items {
-		// Just after rollout, pods from the previous deployment revision may still be in a
-		// terminating state.
-		unavailable := deployment.Status.UnavailableReplicas
-		if unavailable != 0 {
-			return fmt.Errorf("%d replicas unavailable", unavailable)
-		}
+// GetServerVersion returns the Kubernetes version of the server. The discovery client takes no context, so the request
+// is abandoned, rather than cancelled, when the context is done.
+func (k Kubectl) GetServerVersion(context context.Context) (*version.Info, error) {
+	type serverVersion struct {
+		version *version.Info
+		err     error
 	}
+	done := make(chan serverVersion, 1)
+	go func() {
+		version, err := k.clientset.Discovery().ServerVersion()
+		done <- serverVersion{version, err}
+	}()
 
-	return nil
+	select {
+	case <-context.Done():
+		return nil, context.Err()
+	case result := <-done:
+		return result.version, result.err
+	}
 }
 
-func (k Kubectl) DeleteNamespace(context context.Context, namespace string) error {
-	if err := k.clientset.CoreV1().Namespaces().Delete(context, namespace, *metav1.NewDeleteOptions(0)); err != nil {
-		return err
+// getClusterVersion returns the version of the most recent completed update of a ClusterVersion resource, or its
+// desired version while the cluster is being installed.
+func getClusterVersion(clusterVersion *unstructured.Unstructured) (string, error) {
+	// the history is ordered from the most recent update
+	history, _, _ := unstructured.NestedSlice(clusterVersion.Object, "status", "history")
+	for _, entry := range history {
+		if update, ok := entry.(map[string]interface{}); ok && update["state"] == "Completed" {
+			if version, ok := update["version"].(string); ok && version != "" {
+				return version, nil
+			}
+		}
 	}
-	return nil
+	if version, _, _ := unstructured.NestedString(clusterVersion.Object, "status", "desired", "version"); version != "" {
+		return version, nil
+	}
+	return "", fmt.Errorf("no version in ClusterVersion %s", clusterVersion.GetName())
 }
 
-func (k Kubectl) GetServerVersion() (*version.Info, error) {
-	version, err := k.clientset.Discovery().ServerVersion()
-	if err != nil {
-		return nil, err
+// GetOpenShiftVersion returns the version of the OpenShift cluster from its ClusterVersion resource. On plain
+// Kubernetes, which has no ClusterVersion resource, or when the user is not allowed to read it, the OpenShift version
+// matching the Kubernetes version of the server in the given Kubernetes-OpenShift versions is returned.
+func (k Kubectl) GetOpenShiftVersion(context context.Context, versions KubeOpenShiftVersions) (string, error) {
+	clusterVersion, err := k.dynamicClient.Resource(clusterVersionResource).Get(context, "version", metav1.GetOptions{})
+	if err == nil {
+		return getClusterVersion(clusterVersion)
 	}
-	return version, err
-}
+	if !apierrors.IsNotFound(err) && !apierrors.IsForbidden(err) && !meta.IsNoMatchError(err) {
+		return "", err
+	}
+	LogWarning(fmt.Sprintf("ClusterVersion not available, the OpenShift version is approximated from the Kubernetes version : %v", err))
 
-func GetKubeOpenShiftVersionMap() map[string]string {
-	return kubeOpenShiftVersionMap
+	serverVersion, err := k.GetServerVersion(context)
+	if err != nil {
+		return "", err
+	}
+	kubeVersion := fmt.Sprintf("%s.%s", serverVersion.Major, serverVersion.Minor)
+	osVersion, ok := versions.VersionMap()[kubeVersion]
+	if !ok {
+		return "", fmt.Errorf("internal error: %q not found in Kubernetes-OpenShift version map", kubeVersion)
+	}
+	return osVersion, nil
 }
 
 func GetClientConfig(envSettings *cli.EnvSettings) clientcmd.ClientConfig {
@@ -1,12 +1,22 @@
 package tool
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/version"
 	discoveryfake "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 type testData struct {
@@ -44,7 +54,7 @@ func TestOCVersions(t *testing.T) {
 			Minor: testdata.getVersionOut.Minor,
 		}
 		kubectl := Kubectl{clientset: clientset}
-		serverVersion, err := kubectl.GetServerVersion()
+		serverVersion, err := kubectl.GetServerVersion(context.Background())
 		if err != nil {
 			t.Error(err)
 		}
@@ -52,9 +62,108 @@ func TestOCVersions(t *testing.T) {
 			t.Error(fmt.Sprintf("server version mismatch, expected: %+v, got: %+v", testdata.getVersionOut, serverVersion))
 		}
 		kubeVersion := fmt.Sprintf("%s.%s", serverVersion.Major, serverVersion.Minor)
-		ocVersion := GetKubeOpenShiftVersionMap()[kubeVersion]
+		ocVersion := DefaultKubeOpenShiftVersions().VersionMap()[kubeVersion]
 		if ocVersion != testdata.OCVersion {
 			t.Error(fmt.Sprintf("version mismatch, expected: %s, got: %s", testdata.OCVersion, ocVersion))
 		}
 	}
 }
+
+func clusterVersion(history []interface{}, desired string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "config.openshift.io/v1",
+		"kind":       "ClusterVersion",
+		"metadata":   map[string]interface{}{"name": "version"},
+		"status": map[string]interface{}{
+			"desired": map[string]interface{}{"version": desired},
+			"history": history,
+		},
+	}}
+}
+
+func TestGetOpenShiftVersion(t *testing.T) {
+	type testCase struct {
+		description string
+		objects     []runtime.Object
+		reactor     k8stesting.ReactionFunc
+		kubeVersion version.Info
+		osVersion   string
+		err         string
+	}
+
+	testCases := []testCase{
+		{
+			description: "Most recent completed update of the ClusterVersion",
+			objects: []runtime.Object{clusterVersion([]interface{}{
+				map[string]interface{}{"state": "Partial", "version": "4.11.1"},
+				map[string]interface{}{"state": "Completed", "version": "4.10.3"},
+				map[string]interface{}{"state": "Completed", "version": "4.9.12"},
+			}, "4.11.1")},
+			kubeVersion: output122,
+			osVersion:   "4.10.3",
+		},
+		{
+			description: "Desired version of the ClusterVersion during the installation",
+			objects: []runtime.Object{clusterVersion([]interface{}{
+				map[string]interface{}{"state": "Partial", "version": "4.11.0"},
+			}, "4.11.0")},
+			kubeVersion: output122,
+			osVersion:   "4.11.0",
+		},
+		{
+			description: "Kubernetes version without ClusterVersion",
+			kubeVersion: output121,
+			osVersion:   "4.8",
+		},
+		{
+			description: "Kubernetes version when the ClusterVersion cannot be read",
+			objects:     []runtime.Object{clusterVersion(nil, "4.8.2")},
+			reactor: func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewForbidden(clusterVersionResource.GroupResource(), "version", errors.New("not allowed"))
+			},
+			kubeVersion: output121,
+			osVersion:   "4.8",
+		},
+		{
+			description: "Kubernetes version when the ClusterVersion kind is not served",
+			reactor: func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "config.openshift.io", Kind: "ClusterVersion"}}
+			},
+			kubeVersion: output121,
+			osVersion:   "4.8",
+		},
+		{
+			description: "Error reading the ClusterVersion",
+			reactor: func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewInternalError(errors.New("etcd unavailable"))
+			},
+			kubeVersion: output121,
+			err:         "Internal error occurred: etcd unavailable",
+		},
+		{
+			description: "Unknown Kubernetes version without ClusterVersion",
+			kubeVersion: version.Info{Major: "1", Minor: "99"},
+			err:         `internal error: "1.99" not found in Kubernetes-OpenShift version map`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			clientset.Discovery().(*discoveryfake.FakeDiscovery).FakedServerVersion = &tc.kubeVersion
+			dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), tc.objects...)
+			if tc.reactor != nil {
+				dynamicClient.PrependReactor("get", "clusterversions", tc.reactor)
+			}
+			kubectl := Kubectl{clientset: clientset, dynamicClient: dynamicClient}
+
+			osVersion, err := kubectl.GetOpenShiftVersion(context.Background(), DefaultKubeOpenShiftVersions())
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.osVersion, osVersion)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package tool
+
+import (
+	_ "embed"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/Masterminds/semver"
+	"gopkg.in/yaml.v3"
+)
+
+// kubeOpenShiftVersionsFormat is the version of the format of the Kubernetes-OpenShift versions file.
+const kubeOpenShiftVersionsFormat = "v1"
+
+// OpenShiftVersionStatus is the lifecycle status of an OpenShift release.
+type OpenShiftVersionStatus string
+
+const (
+	FullSupportStatus        OpenShiftVersionStatus = "full-support"
+	MaintenanceSupportStatus OpenShiftVersionStatus = "maintenance-support"
+	EndOfLifeStatus          OpenShiftVersionStatus = "end-of-life"
+)
+
+// KubeOpenShiftVersion is an OpenShift release and the Kubernetes version it is based on.
+type KubeOpenShiftVersion struct {
+	KubeVersion      string                 `yaml:"kubeVersion"`
+	OpenShiftVersion string                 `yaml:"openshiftVersion"`
+	Status           OpenShiftVersionStatus `yaml:"status"`
+}
+
+type kubeOpenShiftVersionsFile struct {
+	Version  string                 `yaml:"version"`
+	Versions []KubeOpenShiftVersion `yaml:"versions"`
+}
+
+// defaultKubeOpenShiftVersionsData contains the Kubernetes-OpenShift versions bundled with chart-verifier.
+//
+//go:embed kube-openshift-versions.yaml
+var defaultKubeOpenShiftVersionsData []byte
+
+// defaultKubeOpenShiftVersions are the Kubernetes-OpenShift versions bundled with chart-verifier, never modified.
+var defaultKubeOpenShiftVersions = mustParseKubeOpenShiftVersions(defaultKubeOpenShiftVersionsData)
+
+// KubeOpenShiftVersions are known OpenShift releases, sorted by Kubernetes version.
+type KubeOpenShiftVersions []KubeOpenShiftVersion
+
+// parseKubeOpenShiftVersions parses and validates a Kubernetes-OpenShift versions file, returning the versions sorted
+// by Kubernetes version.
+func parseKubeOpenShiftVersions(data []byte) (KubeOpenShiftVersions, error) {
+	file := kubeOpenShiftVersionsFile{}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if file.Version != kubeOpenShiftVersionsFormat {
+		return nil, fmt.Errorf("version %q not supported, expected %q", file.Version, kubeOpenShiftVersionsFormat)
+	}
+	if len(file.Versions) == 0 {
+		return nil, fmt.Errorf("no versions")
+	}
+
+	kubeVersions := map[string]*semver.Version{}
+	for _, version := range file.Versions {
+		kubeVersion, err := semver.NewVersion(version.KubeVersion)
+		if err != nil {
+			return nil, fmt.Errorf("kubeVersion %q : %v", version.KubeVersion, err)
+		}
+		if _, ok := kubeVersions[version.KubeVersion]; ok {
+			return nil, fmt.Errorf("kubeVersion %q is listed more than once", version.KubeVersion)
+		}
+		kubeVersions[version.KubeVersion] = kubeVersion
+		if _, err := semver.NewVersion(version.OpenShiftVersion); err != nil {
+			return nil, fmt.Errorf("openshiftVersion %q : %v", version.OpenShiftVersion, err)
+		}
+		switch version.Status {
+		case FullSupportStatus, MaintenanceSupportStatus, EndOfLifeStatus:
+		default:
+			return nil, fmt.Errorf("openshiftVersion %q : status %q not supported", version.OpenShiftVersion, version.Status)
+		}
+	}
+
+	versions := KubeOpenShiftVersions(file.Versions)
+	sort.Slice(versions, func(i, j int) bool {
+		return kubeVersions[versions[i].KubeVersion].LessThan(kubeVersions[versions[j].KubeVersion])
+	})
+	return versions, nil
+}
+
+func mustParseKubeOpenShiftVersions(data []byte) KubeOpenShiftVersions {
+	versions, err := parseKubeOpenShiftVersions(data)
+	if err != nil {
+		panic(fmt.Sprintf("bundled Kubernetes-OpenShift versions: %v", err))
+	}
+	return versions
+}
+
+// DefaultKubeOpenShiftVersions returns the Kubernetes-OpenShift versions bundled with chart-verifier.
+func DefaultKubeOpenShiftVersions() KubeOpenShiftVersions {
+	return append(KubeOpenShiftVersions{}, defaultKubeOpenShiftVersions...)
+}
+
+// LoadKubeOpenShiftVersions reads the Kubernetes-OpenShift versions of the given file, or returns the bundled versions
+// when the path is empty.
+func LoadKubeOpenShiftVersions(path string) (KubeOpenShiftVersions, error) {
+	if path == "" {
+		return DefaultKubeOpenShiftVersions(), nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := parseKubeOpenShiftVersions(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", path, err)
+	}
+	return versions, nil
+}
+
+// VersionMap returns the OpenShift version of each Kubernetes version.
+func (v KubeOpenShiftVersions) VersionMap() map[string]string {
+	versionMap := map[string]string{}
+	for _, version := range v {
+		versionMap[version.KubeVersion] = version.OpenShiftVersion
+	}
+	return versionMap
+}
+
+// GetOpenShiftVersionStatus returns the lifecycle status of the release of an OpenShift version, e.g. 4.9 for 4.9.12.
+func (v KubeOpenShiftVersions) GetOpenShiftVersionStatus(openshiftVersion string) (OpenShiftVersionStatus, bool) {
+	version, err := semver.NewVersion(openshiftVersion)
+	if err != nil {
+		return "", false
+	}
+	for _, known := range v {
+		release, err := semver.NewVersion(known.OpenShiftVersion)
+		if err == nil && release.Major() == version.Major() && release.Minor() == version.Minor() {
+			return known.Status, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,83 @@
+package tool
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundledKubeOpenShiftVersions(t *testing.T) {
+	versions := DefaultKubeOpenShiftVersions()
+	require.NotEmpty(t, versions)
+	require.Equal(t, "1.13", versions[0].KubeVersion)
+	require.Equal(t, "1.22", versions[len(versions)-1].KubeVersion)
+	require.Equal(t, "4.9", versions.VersionMap()["1.22"])
+
+	status, ok := versions.GetOpenShiftVersionStatus("4.9.12")
+	require.True(t, ok)
+	require.Equal(t, FullSupportStatus, status)
+	status, ok = versions.GetOpenShiftVersionStatus("4.1")
+	require.True(t, ok)
+	require.Equal(t, EndOfLifeStatus, status)
+	_, ok = versions.GetOpenShiftVersionStatus("3.11")
+	require.False(t, ok)
+
+	// the bundled versions cannot be modified through the returned ones
+	versions[0].OpenShiftVersion = "3.11"
+	require.Equal(t, "4.1", DefaultKubeOpenShiftVersions()[0].OpenShiftVersion)
+}
+
+func TestLoadKubeOpenShiftVersions(t *testing.T) {
+	writeVersions := func(t *testing.T, contents string) string {
+		path := filepath.Join(t.TempDir(), "versions.yaml")
+		require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+		return path
+	}
+
+	t.Run("Versions of a file", func(t *testing.T) {
+		path := writeVersions(t, `version: v1
+versions:
+  - kubeVersion: "1.23"
+    openshiftVersion: "4.10"
+    status: full-support
+  - kubeVersion: "1.9"
+    openshiftVersion: "3.9"
+    status: end-of-life
+`)
+		versions, err := LoadKubeOpenShiftVersions(path)
+		require.NoError(t, err)
+		require.Equal(t, KubeOpenShiftVersions{
+			{KubeVersion: "1.9", OpenShiftVersion: "3.9", Status: EndOfLifeStatus},
+			{KubeVersion: "1.23", OpenShiftVersion: "4.10", Status: FullSupportStatus},
+		}, versions)
+		require.Equal(t, map[string]string{"1.9": "3.9", "1.23": "4.10"}, versions.VersionMap())
+		require.Equal(t, "4.9", DefaultKubeOpenShiftVersions().VersionMap()["1.22"])
+	})
+
+	t.Run("Bundled versions without file", func(t *testing.T) {
+		versions, err := LoadKubeOpenShiftVersions("")
+		require.NoError(t, err)
+		require.Equal(t, DefaultKubeOpenShiftVersions(), versions)
+	})
+
+	invalidFiles := map[string]string{
+		"unsupported format": "version: v2\nversions:\n  - kubeVersion: \"1.23\"\n    openshiftVersion: \"4.10\"\n    status: full-support\n",
+		"no versions":        "version: v1\n",
+		"invalid version":    "version: v1\nversions:\n  - kubeVersion: latest\n    openshiftVersion: \"4.10\"\n    status: full-support\n",
+		"unknown status":     "version: v1\nversions:\n  - kubeVersion: \"1.23\"\n    openshiftVersion: \"4.10\"\n    status: beta\n",
+		"duplicate version":  "version: v1\nversions:\n  - kubeVersion: \"1.23\"\n    openshiftVersion: \"4.10\"\n    status: full-support\n  - kubeVersion: \"1.23\"\n    openshiftVersion: \"4.11\"\n    status: full-support\n",
+	}
+	for description, contents := range invalidFiles {
+		t.Run("Invalid file with "+description, func(t *testing.T) {
+			_, err := LoadKubeOpenShiftVersions(writeVersions(t, contents))
+			require.Error(t, err)
+		})
+	}
+
+	t.Run("Missing file", func(t *testing.T) {
+		_, err := LoadKubeOpenShiftVersions(filepath.Join(t.TempDir(), "missing.yaml"))
+		require.Error(t, err)
+	})
+}
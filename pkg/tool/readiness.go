@@ -0,0 +1,200 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// readinessPollInterval is the interval between two readiness checks of the resources of a release.
+var readinessPollInterval = 2 * time.Second
+
+// replicas returns the desired number of replicas of a workload, which defaults to 1.
+func replicas(specReplicas *int32) int32 {
+	if specReplicas == nil {
+		return 1
+	}
+	return *specReplicas
+}
+
+// getDeploymentReadiness returns why the deployment is not ready, or an empty string when it is. Just after a rollout,
+// pods from the previous revision may still be terminating.
+func getDeploymentReadiness(deployment appsv1.Deployment) string {
+	desired := replicas(deployment.Spec.Replicas)
+	switch {
+	case deployment.Status.ObservedGeneration < deployment.Generation:
+		return "rollout not observed"
+	case deployment.Status.UpdatedReplicas < desired:
+		return fmt.Sprintf("%d/%d replicas updated", deployment.Status.UpdatedReplicas, desired)
+	case deployment.Status.AvailableReplicas < desired || deployment.Status.UnavailableReplicas != 0:
+		return fmt.Sprintf("%d/%d replicas available", deployment.Status.AvailableReplicas, desired)
+	}
+	return ""
+}
+
+// getStatefulSetReadiness returns why the stateful set is not ready, or an empty string when it is.
+func getStatefulSetReadiness(statefulSet appsv1.StatefulSet) string {
+	desired := replicas(statefulSet.Spec.Replicas)
+	updated := desired
+	if rollingUpdate := statefulSet.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil {
+		updated -= *rollingUpdate.Partition
+	}
+	switch {
+	case statefulSet.Status.ObservedGeneration < statefulSet.Generation:
+		return "rollout not observed"
+	case statefulSet.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType && statefulSet.Status.UpdatedReplicas < updated:
+		return fmt.Sprintf("%d/%d replicas updated", statefulSet.Status.UpdatedReplicas, updated)
+	case statefulSet.Status.ReadyReplicas < desired:
+		return fmt.Sprintf("%d/%d replicas ready", statefulSet.Status.ReadyReplicas, desired)
+	}
+	return ""
+}
+
+// getDaemonSetReadiness returns why the daemon set is not ready, or an empty string when it is.
+func getDaemonSetReadiness(daemonSet appsv1.DaemonSet) string {
+	desired := daemonSet.Status.DesiredNumberScheduled
+	switch {
+	case daemonSet.Status.ObservedGeneration < daemonSet.Generation:
+		return "rollout not observed"
+	case daemonSet.Spec.UpdateStrategy.Type != appsv1.OnDeleteDaemonSetStrategyType && daemonSet.Status.UpdatedNumberScheduled < desired:
+		return fmt.Sprintf("%d/%d pods updated", daemonSet.Status.UpdatedNumberScheduled, desired)
+	case daemonSet.Status.NumberReady < desired:
+		return fmt.Sprintf("%d/%d pods ready", daemonSet.Status.NumberReady, desired)
+	}
+	return ""
+}
+
+// getJobReadiness returns why the job is not complete, or an empty string when it is, and whether it has failed.
+func getJobReadiness(job batchv1.Job) (string, bool) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return "", false
+		case batchv1.JobFailed:
+			return strings.TrimSpace(fmt.Sprintf("failed %s %s", condition.Reason, condition.Message)), true
+		}
+	}
+	return fmt.Sprintf("%d/%d completions", job.Status.Succeeded, replicas(job.Spec.Completions)), false
+}
+
+// getNotReadyResources lists the deployments, stateful sets, daemon sets, jobs, persistent volume claims and services
+// matching the selector, returning a description of each one which is not ready yet. An error is returned when the
+// resources cannot be listed or a job has failed, as waiting longer would not help.
+func (k Kubectl) getNotReadyResources(ctx context.Context, namespace, selector string) ([]string, error) {
+	listOptions := metav1.ListOptions{LabelSelector: selector}
+	var notReady []string
+	addNotReady := func(kind, name, reason string) {
+		if reason != "" {
+			notReady = append(notReady, fmt.Sprintf("%s/%s : %s", kind, name, reason))
+		}
+	}
+
+	deployments, err := k.clientset.AppsV1().Deployments(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments.Items {
+		addNotReady("Deployment", deployment.Name, getDeploymentReadiness(deployment))
+	}
+
+	statefulSets, err := k.clientset.AppsV1().StatefulSets(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		addNotReady("StatefulSet", statefulSet.Name, getStatefulSetReadiness(statefulSet))
+	}
+
+	daemonSets, err := k.clientset.AppsV1().DaemonSets(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, daemonSet := range daemonSets.Items {
+		addNotReady("DaemonSet", daemonSet.Name, getDaemonSetReadiness(daemonSet))
+	}
+
+	jobs, err := k.clientset.BatchV1().Jobs(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs.Items {
+		reason, failed := getJobReadiness(job)
+		if failed {
+			return nil, fmt.Errorf("Job/%s : %s", job.Name, reason)
+		}
+		addNotReady("Job", job.Name, reason)
+	}
+
+	pvcs, err := k.clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, pvc := range pvcs.Items {
+		if pvc.Status.Phase != corev1.ClaimBound {
+			addNotReady("PersistentVolumeClaim", pvc.Name, fmt.Sprintf("%s, not bound", pvc.Status.Phase))
+		}
+	}
+
+	services, err := k.clientset.CoreV1().Services(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, service := range services.Items {
+		// only the services selecting pods have endpoints managed by the cluster
+		if service.Spec.Type == corev1.ServiceTypeExternalName || len(service.Spec.Selector) == 0 {
+			continue
+		}
+		endpoints, err := k.clientset.CoreV1().Endpoints(namespace).Get(ctx, service.Name, metav1.GetOptions{})
+		if err != nil {
+			addNotReady("Service", service.Name, "no endpoints")
+			continue
+		}
+		ready := false
+		for _, subset := range endpoints.Subsets {
+			ready = ready || len(subset.Addresses) > 0
+		}
+		if !ready {
+			addNotReady("Service", service.Name, "no ready endpoints")
+		}
+	}
+
+	return notReady, nil
+}
+
+// WaitForResources polls the deployments, stateful sets, daemon sets, jobs, persistent volume claims and services
+// matching the selector until they are all ready, failing when the context is done, or when the default timeout has
+// elapsed if the context has no deadline. The error lists the resources which are still not ready.
+func (k Kubectl) WaitForResources(ctx context.Context, namespace, selector string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeoutFromContext(ctx))
+	defer cancel()
+
+	logged := ""
+	for {
+		notReady, err := k.getNotReadyResources(ctx, namespace, selector)
+		if err != nil {
+			return err
+		}
+		if len(notReady) == 0 {
+			return nil
+		}
+		// log only the changes, the resources may not be ready for minutes
+		if waiting := strings.Join(notReady, ", "); waiting != logged {
+			LogInfo(fmt.Sprintf("Waiting for %s", waiting))
+			logged = waiting
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s not ready : %w", strings.Join(notReady, ", "), ctx.Err())
+		case <-time.After(readinessPollInterval):
+		}
+	}
+}
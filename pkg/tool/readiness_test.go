@@ -0,0 +1,153 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var releaseLabels = map[string]string{"app.kubernetes.io/instance": "release"}
+
+func releaseMeta(name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Name: name, Namespace: "default", Labels: releaseLabels, Generation: 1}
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+// readyResources returns a ready resource of each kind waited for.
+func readyResources() []runtime.Object {
+	return []runtime.Object{
+		&appsv1.Deployment{
+			ObjectMeta: releaseMeta("app"),
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2, ReadyReplicas: 2},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: releaseMeta("db"),
+			Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(3)},
+			Status:     appsv1.StatefulSetStatus{ObservedGeneration: 1, Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 3},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: releaseMeta("agent"),
+			Status:     appsv1.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 2, UpdatedNumberScheduled: 2, NumberReady: 2},
+		},
+		&batchv1.Job{
+			ObjectMeta: releaseMeta("migrate"),
+			Status:     batchv1.JobStatus{Succeeded: 1, Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: releaseMeta("data"),
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		},
+		&corev1.Service{
+			ObjectMeta: releaseMeta("app"),
+			Spec:       corev1.ServiceSpec{Selector: releaseLabels},
+		},
+		&corev1.Endpoints{
+			ObjectMeta: releaseMeta("app"),
+			Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}},
+		},
+		&corev1.Service{
+			ObjectMeta: releaseMeta("external"),
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName, ExternalName: "example.com"},
+		},
+	}
+}
+
+func TestWaitForResources(t *testing.T) {
+	readinessPollInterval = 10 * time.Millisecond
+	selector := "app.kubernetes.io/instance=release"
+
+	t.Run("Ready resources", func(t *testing.T) {
+		kubectl := Kubectl{clientset: fake.NewSimpleClientset(readyResources()...)}
+		require.NoError(t, kubectl.WaitForResources(context.Background(), "default", selector))
+	})
+
+	t.Run("Resources of other releases are ignored", func(t *testing.T) {
+		other := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default", Labels: map[string]string{"app.kubernetes.io/instance": "other"}},
+		}
+		kubectl := Kubectl{clientset: fake.NewSimpleClientset(append(readyResources(), other)...)}
+		require.NoError(t, kubectl.WaitForResources(context.Background(), "default", selector))
+	})
+
+	t.Run("Resources not ready before the deadline", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(
+			&appsv1.StatefulSet{
+				ObjectMeta: releaseMeta("db"),
+				Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(3)},
+				Status:     appsv1.StatefulSetStatus{ObservedGeneration: 1, UpdatedReplicas: 3},
+			},
+			&appsv1.DaemonSet{
+				ObjectMeta: releaseMeta("agent"),
+				Status:     appsv1.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 2, UpdatedNumberScheduled: 2, NumberReady: 1},
+			},
+			&batchv1.Job{ObjectMeta: releaseMeta("migrate")},
+			&corev1.PersistentVolumeClaim{
+				ObjectMeta: releaseMeta("data"),
+				Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+			},
+			&corev1.Service{
+				ObjectMeta: releaseMeta("app"),
+				Spec:       corev1.ServiceSpec{Selector: releaseLabels},
+			},
+		)
+		kubectl := Kubectl{clientset: clientset}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := kubectl.WaitForResources(ctx, "default", selector)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+		require.Contains(t, err.Error(), "StatefulSet/db : 0/3 replicas ready")
+		require.Contains(t, err.Error(), "DaemonSet/agent : 1/2 pods ready")
+		require.Contains(t, err.Error(), "Job/migrate : 0/1 completions")
+		require.Contains(t, err.Error(), "PersistentVolumeClaim/data : Pending, not bound")
+		require.Contains(t, err.Error(), "Service/app : no endpoints")
+	})
+
+	t.Run("Resources becoming ready are polled", func(t *testing.T) {
+		statefulSet := &appsv1.StatefulSet{
+			ObjectMeta: releaseMeta("db"),
+			Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(1)},
+			Status:     appsv1.StatefulSetStatus{ObservedGeneration: 1, UpdatedReplicas: 1},
+		}
+		clientset := fake.NewSimpleClientset(statefulSet)
+		kubectl := Kubectl{clientset: clientset}
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			ready := statefulSet.DeepCopy()
+			ready.Status.ReadyReplicas = 1
+			_, _ = clientset.AppsV1().StatefulSets("default").UpdateStatus(context.Background(), ready, metav1.UpdateOptions{})
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		require.NoError(t, kubectl.WaitForResources(ctx, "default", selector))
+	})
+
+	t.Run("Failed job", func(t *testing.T) {
+		job := &batchv1.Job{
+			ObjectMeta: releaseMeta("migrate"),
+			Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded", Message: "Job has reached the specified backoff limit"},
+			}},
+		}
+		kubectl := Kubectl{clientset: fake.NewSimpleClientset(job)}
+
+		err := kubectl.WaitForResources(context.Background(), "default", selector)
+		require.EqualError(t, err, "Job/migrate : failed BackoffLimitExceeded Job has reached the specified backoff limit")
+	})
+}
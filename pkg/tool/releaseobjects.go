@@ -0,0 +1,121 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// ignoredResources are the resources whose objects are never release objects, or are only derived from them and
+// deleted by the cluster, keyed by group and resource.
+var ignoredResources = map[string]bool{
+	"events":                          true,
+	"events.k8s.io/events":            true,
+	"endpoints":                       true,
+	"discovery.k8s.io/endpointslices": true,
+}
+
+// ReleaseObject is an object carrying the label of a release.
+type ReleaseObject struct {
+	Kind      string
+	Namespace string
+	Name      string
+	// Hook is whether the object is a Helm hook, which Helm does not delete with the release.
+	Hook bool
+	// Terminating is whether the object is being deleted, e.g. waiting for a finalizer.
+	Terminating bool
+}
+
+func (o ReleaseObject) String() string {
+	name := o.Name
+	if o.Namespace != "" {
+		name = o.Namespace + "/" + o.Name
+	}
+	return fmt.Sprintf("%s %s", o.Kind, name)
+}
+
+// ListReleaseObjects lists the objects matching the selector, of every resource which can be listed: in the namespace
+// for the namespaced resources and in the whole cluster for the cluster-scoped ones. The resources the user is not
+// allowed to list are skipped.
+func (k Kubectl) ListReleaseObjects(context context.Context, namespace, selector string) ([]ReleaseObject, error) {
+	_, resourceLists, err := k.clientset.Discovery().ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var objects []ReleaseObject
+	for _, resourceList := range resourceLists {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range resourceList.APIResources {
+			key := resource.Name
+			if groupVersion.Group != "" {
+				key = groupVersion.Group + "/" + resource.Name
+			}
+			// subresources, such as deployments/status, are not objects
+			if strings.Contains(resource.Name, "/") || ignoredResources[key] || !hasVerb(resource.Verbs, "list") {
+				continue
+			}
+
+			client := k.dynamicClient.Resource(groupVersion.WithResource(resource.Name))
+			listOptions := metav1.ListOptions{LabelSelector: selector}
+			objectNamespace := ""
+			if resource.Namespaced {
+				objectNamespace = namespace
+			}
+			items, err := client.Namespace(objectNamespace).List(context, listOptions)
+			if err != nil {
+				if apierrors.IsForbidden(err) || apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+					continue
+				}
+				return nil, fmt.Errorf("listing %s : %w", key, err)
+			}
+			for _, item := range items.Items {
+				// the same objects may be served by several versions of a group
+				id := string(item.GetUID())
+				if id == "" {
+					id = fmt.Sprintf("%s/%s/%s/%s", groupVersion.Group, item.GetKind(), item.GetNamespace(), item.GetName())
+				}
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+				_, hook := item.GetAnnotations()[testHookAnnotation]
+				kind := item.GetKind()
+				if kind == "" {
+					kind = resource.Kind
+				}
+				objects = append(objects, ReleaseObject{
+					Kind:        kind,
+					Namespace:   item.GetNamespace(),
+					Name:        item.GetName(),
+					Hook:        hook,
+					Terminating: item.GetDeletionTimestamp() != nil,
+				})
+			}
+		}
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].String() < objects[j].String()
+	})
+	return objects, nil
+}
+
+func hasVerb(verbs metav1.Verbs, verb string) bool {
+	for _, v := range verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
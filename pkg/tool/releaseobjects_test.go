@@ -0,0 +1,94 @@
+package tool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func unstructuredObject(apiVersion, kind, namespace, name string, labels map[string]interface{}) *unstructured.Unstructured {
+	metadata := map[string]interface{}{"name": name, "labels": labels}
+	if namespace != "" {
+		metadata["namespace"] = namespace
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": apiVersion, "kind": kind, "metadata": metadata}}
+}
+
+func TestListReleaseObjects(t *testing.T) {
+	releaseLabels := map[string]interface{}{"app.kubernetes.io/instance": "release"}
+	listVerbs := metav1.Verbs{"get", "list", "delete"}
+
+	hook := unstructuredObject("v1", "Pod", "shared", "release-test-connection", releaseLabels)
+	hook.SetAnnotations(map[string]string{"helm.sh/hook": "test"})
+	terminating := unstructuredObject("v1", "ConfigMap", "shared", "release-config", releaseLabels)
+	terminating.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+	terminating.SetFinalizers([]string{"example.com/finalizer"})
+
+	objects := []runtime.Object{
+		unstructuredObject("v1", "PersistentVolumeClaim", "shared", "data-release-db-0", releaseLabels),
+		unstructuredObject("v1", "PersistentVolumeClaim", "shared", "data-other-db-0", map[string]interface{}{"app.kubernetes.io/instance": "other"}),
+		unstructuredObject("v1", "PersistentVolumeClaim", "elsewhere", "data-release-db-0", releaseLabels),
+		unstructuredObject("v1", "Endpoints", "shared", "release-app", releaseLabels),
+		hook,
+		terminating,
+		unstructuredObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "release-reader", releaseLabels),
+		unstructuredObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "admin", nil),
+		unstructuredObject("admissionregistration.k8s.io/v1", "ValidatingWebhookConfiguration", "", "release-webhook", releaseLabels),
+		unstructuredObject("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", "widgets.example.com", releaseLabels),
+	}
+
+	clientset := fake.NewSimpleClientset()
+	clientset.Discovery().(*discoveryfake.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "persistentvolumeclaims", Kind: "PersistentVolumeClaim", Namespaced: true, Verbs: listVerbs},
+			{Name: "persistentvolumeclaims/status", Kind: "PersistentVolumeClaim", Namespaced: true, Verbs: metav1.Verbs{"get"}},
+			{Name: "endpoints", Kind: "Endpoints", Namespaced: true, Verbs: listVerbs},
+			{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: listVerbs},
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: listVerbs},
+			{Name: "bindings", Kind: "Binding", Namespaced: true, Verbs: metav1.Verbs{"create"}},
+		}},
+		{GroupVersion: "rbac.authorization.k8s.io/v1", APIResources: []metav1.APIResource{
+			{Name: "clusterroles", Kind: "ClusterRole", Verbs: listVerbs},
+		}},
+		{GroupVersion: "admissionregistration.k8s.io/v1", APIResources: []metav1.APIResource{
+			{Name: "validatingwebhookconfigurations", Kind: "ValidatingWebhookConfiguration", Verbs: listVerbs},
+		}},
+		{GroupVersion: "apiextensions.k8s.io/v1", APIResources: []metav1.APIResource{
+			{Name: "customresourcedefinitions", Kind: "CustomResourceDefinition", Verbs: listVerbs},
+		}},
+	}
+	listKinds := map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "persistentvolumeclaims"}:                                                 "PersistentVolumeClaimList",
+		{Version: "v1", Resource: "endpoints"}:                                                              "EndpointsList",
+		{Version: "v1", Resource: "pods"}:                                                                   "PodList",
+		{Version: "v1", Resource: "configmaps"}:                                                             "ConfigMapList",
+		{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}:                       "ClusterRoleList",
+		{Group: "admissionregistration.k8s.io", Version: "v1", Resource: "validatingwebhookconfigurations"}: "ValidatingWebhookConfigurationList",
+		{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}:               "CustomResourceDefinitionList",
+	}
+	kubectl := Kubectl{
+		clientset:     clientset,
+		dynamicClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...),
+	}
+
+	releaseObjects, err := kubectl.ListReleaseObjects(context.Background(), "shared", "app.kubernetes.io/instance=release")
+	require.NoError(t, err)
+	require.Equal(t, []ReleaseObject{
+		{Kind: "ClusterRole", Name: "release-reader"},
+		{Kind: "ConfigMap", Namespace: "shared", Name: "release-config", Terminating: true},
+		{Kind: "CustomResourceDefinition", Name: "widgets.example.com"},
+		{Kind: "PersistentVolumeClaim", Namespace: "shared", Name: "data-release-db-0"},
+		{Kind: "Pod", Namespace: "shared", Name: "release-test-connection", Hook: true},
+		{Kind: "ValidatingWebhookConfiguration", Name: "release-webhook"},
+	}, releaseObjects)
+	require.Equal(t, "PersistentVolumeClaim shared/data-release-db-0", releaseObjects[3].String())
+}
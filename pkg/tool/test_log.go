@@ -3,6 +3,8 @@ package tool
 import (
 	"encoding/json"
 	"fmt"
+	"sync"
+
 	"gopkg.in/yaml.v3"
 )
 
@@ -17,25 +19,33 @@ type LogEntry struct {
 
 var testlog TestLog
 
+// testlogMutex guards testlog, checks running concurrently log through it.
+var testlogMutex sync.Mutex
+
 func init() {
 	testlog = TestLog{Name: "Chart Verifier Log"}
 }
 
+func addLogEntry(entry LogEntry) {
+	testlogMutex.Lock()
+	defer testlogMutex.Unlock()
+	testlog.Entries = append(testlog.Entries, &entry)
+}
+
 func LogWarning(message string) {
-	warning_log_entry := LogEntry{Entry: fmt.Sprintf("[WARNING] %s", message)}
-	testlog.Entries = append(testlog.Entries, &warning_log_entry)
+	addLogEntry(LogEntry{Entry: fmt.Sprintf("[WARNING] %s", message)})
 }
 
 func LogInfo(message string) {
-	info_log_entry := LogEntry{Entry: fmt.Sprintf("[INFO] %s", message)}
-	testlog.Entries = append(testlog.Entries, &info_log_entry)
+	addLogEntry(LogEntry{Entry: fmt.Sprintf("[INFO] %s", message)})
 }
 
 func LogError(message string) {
-	error_log_entry := LogEntry{Entry: fmt.Sprintf("[ERROR} %s", message)}
-	testlog.Entries = append(testlog.Entries, &error_log_entry)
+	addLogEntry(LogEntry{Entry: fmt.Sprintf("[ERROR} %s", message)})
 }
 func GetLogsOutput(log_format string) (string, error) {
+	testlogMutex.Lock()
+	defer testlogMutex.Unlock()
 
 	if len(testlog.Entries) > 0 {
 		if log_format == "json" {